@@ -17,19 +17,27 @@ limitations under the License.
 package main
 
 import (
-	"net/http"
+	"context"
+	"flag"
 	"time"
 
 	"k8s.io/apimachinery/pkg/util/runtime"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/client-go/util/workqueue"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 
 	"github.com/litmuschaos/chaos-exporter/controller"
 	"github.com/litmuschaos/chaos-exporter/pkg/clients"
+	"github.com/litmuschaos/chaos-exporter/pkg/health"
+	"github.com/litmuschaos/chaos-exporter/pkg/leader"
 	"github.com/litmuschaos/chaos-exporter/pkg/log"
+	"github.com/litmuschaos/chaos-exporter/pkg/otlp"
+	"github.com/litmuschaos/chaos-exporter/pkg/remotewrite"
+	"github.com/litmuschaos/chaos-exporter/pkg/server"
+	"github.com/litmuschaos/chaos-exporter/pkg/webhook"
 )
 
 func init() {
@@ -46,6 +54,39 @@ func main() {
 	defer close(stop)
 	defer runtime.HandleCrash()
 
+	serverConfig := server.Config{}
+	healthConfig := health.Config{}
+	leaderConfig := leader.Config{}
+	otlpConfig := otlp.Config{}
+	remoteWriteConfig := remotewrite.Config{}
+	webhookConfig := webhook.Config{}
+	clients.RegisterFlags(flag.CommandLine)
+	serverConfig.RegisterFlags(flag.CommandLine)
+	healthConfig.RegisterFlags(flag.CommandLine)
+	leaderConfig.RegisterFlags(flag.CommandLine)
+	otlpConfig.RegisterFlags(flag.CommandLine)
+	remoteWriteConfig.RegisterFlags(flag.CommandLine)
+	webhookConfig.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	healthChecker := health.NewChecker(healthConfig)
+	// the replica stays in standby until it acquires the leadership
+	healthChecker.SetStandby(leaderConfig.Enabled)
+	if leaderConfig.Enabled {
+		prometheus.MustRegister(leader.IsLeader)
+	}
+
+	//This section will start the HTTP server and expose metrics on the metrics path.
+	//It is started before the informers sync, so that the health checks are served meanwhile
+	srv := server.New(serverConfig)
+	srv.HandleMetrics(promhttp.Handler())
+	srv.Handle("/healthz", healthChecker.LivenessHandler())
+	srv.Handle("/readyz", healthChecker.ReadinessHandler())
+	go func() {
+		log.Infof("Beginning to serve %s on %s, tls enabled: %v", serverConfig.MetricsPath, serverConfig.ListenAddress, serverConfig.TLSEnabled())
+		log.Fatal(srv.ListenAndServe())
+	}()
+
 	wq := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
 	defer wq.ShutDown()
 
@@ -54,12 +95,80 @@ func main() {
 	if err != nil {
 		log.Fatalf("Unable to Get the kubeconfig, err: %v", err)
 	}
+	healthChecker.SetInformersSynced(clientset.InformersSynced)
+
+	stateBackend, err := controller.NewStateBackendFromEnv(clientset.KubeClient)
+	if err != nil {
+		log.Fatalf("Unable to create the state backend, err: %v", err)
+	}
+
+	sinks, err := controller.NewSinkRegistry().BuildFromEnv()
+	if err != nil {
+		log.Fatalf("Unable to create the metrics sinks, err: %v", err)
+	}
+
+	notifiers, err := controller.NewVerdictNotifiersFromEnv(clientset)
+	if err != nil {
+		log.Fatalf("Unable to create the verdict notifiers, err: %v", err)
+	}
+	var webhookNotifier *webhook.Notifier
+	if webhookConfig.Enabled() {
+		if webhookNotifier, err = webhook.NewNotifier(webhookConfig); err != nil {
+			log.Fatalf("Unable to create the webhook notifier, err: %v", err)
+		}
+		notifiers = append(notifiers, webhookNotifier)
+	}
+
+	var otlpExporter *otlp.Exporter
+	if otlpConfig.Enabled() {
+		if otlpExporter, err = otlp.NewExporter(otlpConfig, prometheus.DefaultGatherer); err != nil {
+			log.Fatalf("Unable to create the OTLP exporter, err: %v", err)
+		}
+	}
+
+	var remoteWriteClient *remotewrite.Client
+	if remoteWriteConfig.Enabled() {
+		if remoteWriteClient, err = remotewrite.NewClient(remoteWriteConfig, prometheus.DefaultGatherer); err != nil {
+			log.Fatalf("Unable to create the remote-write client, err: %v", err)
+		}
+	}
 
-	// Trigger the chaos metrics collection
-	go controller.Exporter(clientset, wq)
+	options := controller.ExporterOptions{
+		Health:       healthChecker,
+		StateBackend: stateBackend,
+		Sinks:        sinks,
+		Notifiers:    notifiers,
+	}
+
+	// collect triggers the chaos metrics collection, pushes the metrics to the OTLP collector
+	// and the remote-write endpoint and sends the webhook notifications
+	collect := func(ctx context.Context) {
+		if webhookNotifier != nil {
+			go webhookNotifier.Run(ctx)
+		}
+		if otlpExporter != nil {
+			log.Infof("Pushing the metrics to the OTLP collector %s over %s every %v", otlpConfig.Endpoint, otlpConfig.Protocol, otlpConfig.Interval)
+			go otlpExporter.Run(ctx)
+		}
+		if remoteWriteClient != nil {
+			log.Infof("Pushing the metrics to the remote-write endpoint %s every %v", remoteWriteConfig.URL, remoteWriteConfig.Interval)
+			go remoteWriteClient.Run(ctx)
+		}
+		controller.Exporter(clientset, wq, options)
+	}
 
-	//This section will start the HTTP server and expose metrics on the /metrics endpoint.
-	http.Handle("/metrics", promhttp.Handler())
-	log.Info("Beginning to serve on port :8080")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	if !leaderConfig.Enabled {
+		collect(context.Background())
+		return
+	}
+
+	// Only the leader collects the chaos metrics, the standby replicas keep the informers warm
+	// so that the new leader starts collecting without waiting for the caches to sync
+	log.Infof("[Standby]: %s is waiting for the leadership of lease %s/%s", leaderConfig.Identity, leaderConfig.LeaseNamespace, leaderConfig.LeaseName)
+	if err := leader.Run(context.Background(), leaderConfig, clientset.KubeClient, func(ctx context.Context) {
+		healthChecker.SetStandby(false)
+		collect(ctx)
+	}); err != nil {
+		log.Fatalf("Unable to run the leader election, err: %v", err)
+	}
 }
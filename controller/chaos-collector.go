@@ -0,0 +1,314 @@
+/*
+Copyright 2019 LitmusChaos Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/log"
+)
+
+// resultSnapshot contains the metrics data of a chaosresult
+type resultSnapshot struct {
+	details ChaosResultDetails
+	// verdictValue is the value of the verdict metric, it is reset to 0 once the verdict is exported for the scrape interval
+	verdictValue float64
+	transitions  probeTransitions
+}
+
+// metricsSnapshot contains the metrics data of all the chaosresults, it is never modified once stored
+type metricsSnapshot struct {
+	// results contains the metrics data of the chaosresults, keyed by the chaosresult key
+	results    map[string]resultSnapshot
+	aggregates NamespacedScopeMetrics
+}
+
+// clone creates a copy of the snapshot, which can be modified
+func (snapshot *metricsSnapshot) clone() *metricsSnapshot {
+	results := make(map[string]resultSnapshot, len(snapshot.results))
+	for key, result := range snapshot.results {
+		results[key] = result
+	}
+	return &metricsSnapshot{
+		results:    results,
+		aggregates: snapshot.aggregates,
+	}
+}
+
+// aggregateDescs contains the descriptions of the aggregate metrics
+type aggregateDescs struct {
+	passedExperiments         *prometheus.Desc
+	failedExperiments         *prometheus.Desc
+	awaitedExperiments        *prometheus.Desc
+	stoppedExperiments        *prometheus.Desc
+	experimentsRunCount       *prometheus.Desc
+	experimentsInstalledCount *prometheus.Desc
+}
+
+// newAggregateDescs creates the descriptions of the aggregate metrics for the given subsystem
+func newAggregateDescs(subsystem, scope string, labels []string) aggregateDescs {
+	name := func(name string) string {
+		return prometheus.BuildFQName("litmuschaos", subsystem, name)
+	}
+	return aggregateDescs{
+		passedExperiments:         prometheus.NewDesc(name("passed_experiments"), "Total number of passed experiments in "+scope, labels, nil),
+		failedExperiments:         prometheus.NewDesc(name("failed_experiments"), "Total number of failed experiments in "+scope, labels, nil),
+		awaitedExperiments:        prometheus.NewDesc(name("awaited_experiments"), "Total number of awaited experiments in "+scope, labels, nil),
+		stoppedExperiments:        prometheus.NewDesc(name("stopped_experiments"), "Total number of stopped experiments in "+scope, labels, nil),
+		experimentsRunCount:       prometheus.NewDesc(name("experiments_run_count"), "Total experiments run in "+scope, labels, nil),
+		experimentsInstalledCount: prometheus.NewDesc(name("experiments_installed_count"), "Total number of experiments in "+scope, labels, nil),
+	}
+}
+
+// describe sends the descriptions of the aggregate metrics
+func (descs aggregateDescs) describe(ch chan<- *prometheus.Desc) {
+	ch <- descs.passedExperiments
+	ch <- descs.failedExperiments
+	ch <- descs.awaitedExperiments
+	ch <- descs.stoppedExperiments
+	ch <- descs.experimentsRunCount
+	ch <- descs.experimentsInstalledCount
+}
+
+// ChaosMetricsCollector exports the chaosresult and the aggregate metrics
+// The metrics are rendered on every scrape from the snapshot, which is swapped atomically at the end of every reconcile
+// so that a scrape never observes a partially updated chaosresult and the metrics of the deleted chaosresults disappear with it
+type ChaosMetricsCollector struct {
+	// watchNamespace is the namespace of the namespace scoped aggregate metrics, the aggregates are cluster scoped if it is empty
+	watchNamespace string
+
+	// mu serializes the snapshot updates, the scrapes only load the snapshot
+	mu       sync.Mutex
+	snapshot atomic.Value
+
+	passedExperiments            *prometheus.Desc
+	failedExperiments            *prometheus.Desc
+	awaitedExperiments           *prometheus.Desc
+	stoppedExperiments           *prometheus.Desc
+	probeSuccessPercentage       *prometheus.Desc
+	verdict                      *prometheus.Desc
+	failureInfo                  *prometheus.Desc
+	probeVerdict                 *prometheus.Desc
+	probeStatus                  *prometheus.Desc
+	probeTransitions             *prometheus.Desc
+	probeLastTransitionTimestamp *prometheus.Desc
+	targetInfo                   *prometheus.Desc
+	targetChaosStatus            *prometheus.Desc
+	startTime                    *prometheus.Desc
+	endTime                      *prometheus.Desc
+	chaosInjectedTime            *prometheus.Desc
+	totalDuration                *prometheus.Desc
+	timingSource                 *prometheus.Desc
+	namespaceScoped              aggregateDescs
+	clusterScoped                aggregateDescs
+}
+
+// NewChaosMetricsCollector creates the collector with an empty snapshot
+func NewChaosMetricsCollector(watchNamespace string) *ChaosMetricsCollector {
+	resultLabels := []string{"chaosresult_namespace", "chaosresult_name", "chaosengine_name", "chaosengine_context", "fault_name"}
+	transitionLabels := []string{"chaosresult_namespace", "chaosresult_name", "chaosresult_uid", "probe_name", "probe_mode"}
+	c := &ChaosMetricsCollector{
+		watchNamespace: watchNamespace,
+		passedExperiments: prometheus.NewDesc("litmuschaos_passed_experiments",
+			"Total number of passed experiments", resultLabels, nil),
+		failedExperiments: prometheus.NewDesc("litmuschaos_failed_experiments",
+			"Total number of failed experiments", resultLabels, nil),
+		awaitedExperiments: prometheus.NewDesc("litmuschaos_awaited_experiments",
+			"Total number of awaited experiments",
+			[]string{"chaosresult_namespace", "chaosresult_name", "chaosengine_name", "chaosengine_context", "workflow_name", "fault_name"}, nil),
+		stoppedExperiments: prometheus.NewDesc("litmuschaos_stopped_experiments",
+			"Total number of stopped experiments", resultLabels, nil),
+		probeSuccessPercentage: prometheus.NewDesc("litmuschaos_probe_success_percentage",
+			"ProbeSuccessPercentage for the experiments", resultLabels, nil),
+		verdict: prometheus.NewDesc("litmuschaos_experiment_verdict",
+			"Verdict of the experiments",
+			[]string{"chaosresult_namespace", "chaosresult_name", "chaosengine_name", "chaosengine_context", "chaosresult_verdict",
+				"probe_success_percentage", "app_label", "app_namespace", "app_kind", "workflow_name", "fault_name"}, nil),
+		failureInfo: prometheus.NewDesc("litmuschaos_experiment_failure_info",
+			"Error code and failure category of the failed experiments",
+			append(resultLabels, "error_code", "failure_category"), nil),
+		probeVerdict: prometheus.NewDesc("litmuschaos_probe_verdict",
+			"Verdict of the individual probes",
+			append(resultLabels, "probe_name", "probe_type", "probe_mode", "probe_verdict"), nil),
+		probeStatus: prometheus.NewDesc("litmuschaos_probe_status",
+			"Status of the individual probes, 1 for passed, 0 for failed and -1 for awaited or N/A",
+			append(resultLabels, "probe_name", "probe_type", "probe_mode"), nil),
+		probeTransitions: prometheus.NewDesc("litmuschaos_probe_transitions_total",
+			"Total number of the verdict transitions of the individual probes, labelled by the new verdict",
+			append(transitionLabels, "probe_verdict"), nil),
+		probeLastTransitionTimestamp: prometheus.NewDesc("litmuschaos_probe_last_transition_timestamp",
+			"Unix timestamp of the last verdict transition of the individual probes", transitionLabels, nil),
+		targetInfo: prometheus.NewDesc("litmuschaos_experiment_target_info",
+			"Targets of the experiments along with their chaos status",
+			append(resultLabels, "target_name", "target_kind", "target_chaos_status"), nil),
+		targetChaosStatus: prometheus.NewDesc("litmuschaos_experiment_target_chaos_status",
+			"Chaos status of the individual targets, 0 for targeted, 1 for injected, 2 for reverted and -1 for unknown",
+			append(resultLabels, "target_name", "target_kind"), nil),
+		startTime: prometheus.NewDesc("litmuschaos_experiment_start_time",
+			"start time of the experiments", resultLabels, nil),
+		endTime: prometheus.NewDesc("litmuschaos_experiment_end_time",
+			"end time of the experiments", resultLabels, nil),
+		chaosInjectedTime: prometheus.NewDesc("litmuschaos_experiment_chaos_injected_time",
+			"chaos injected time of the experiments", resultLabels, nil),
+		totalDuration: prometheus.NewDesc("litmuschaos_experiment_total_duration",
+			"total duration of the experiments", resultLabels, nil),
+		timingSource: prometheus.NewDesc("litmuschaos_experiment_timing_source",
+			"Source of the experiment timings, labelled by the timing and its source",
+			append(resultLabels, "timing", "source"), nil),
+		namespaceScoped: newAggregateDescs("namespace_scoped", "watch namespace", []string{"chaosresult_namespace"}),
+		clusterScoped:   newAggregateDescs("cluster_scoped", "all namespaces", nil),
+	}
+	c.snapshot.Store(&metricsSnapshot{results: map[string]resultSnapshot{}})
+	return c
+}
+
+// load returns the current snapshot, it must not be modified
+func (c *ChaosMetricsCollector) load() *metricsSnapshot {
+	return c.snapshot.Load().(*metricsSnapshot)
+}
+
+// update applies the given changes on a copy of the current snapshot and swaps it atomically
+func (c *ChaosMetricsCollector) update(apply func(snapshot *metricsSnapshot)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	snapshot := c.load().clone()
+	apply(snapshot)
+	c.snapshot.Store(snapshot)
+}
+
+// Describe implements the prometheus.Collector interface
+func (c *ChaosMetricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.passedExperiments
+	ch <- c.failedExperiments
+	ch <- c.awaitedExperiments
+	ch <- c.stoppedExperiments
+	ch <- c.probeSuccessPercentage
+	ch <- c.verdict
+	ch <- c.failureInfo
+	ch <- c.probeVerdict
+	ch <- c.probeStatus
+	ch <- c.probeTransitions
+	ch <- c.probeLastTransitionTimestamp
+	ch <- c.targetInfo
+	ch <- c.targetChaosStatus
+	ch <- c.startTime
+	ch <- c.endTime
+	ch <- c.chaosInjectedTime
+	ch <- c.totalDuration
+	ch <- c.timingSource
+	c.namespaceScoped.describe(ch)
+	c.clusterScoped.describe(ch)
+}
+
+// Collect implements the prometheus.Collector interface
+func (c *ChaosMetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	snapshot := c.load()
+	for _, result := range snapshot.results {
+		c.collectResult(ch, result)
+	}
+	c.collectAggregates(ch, snapshot.aggregates)
+}
+
+// collectResult sends the metrics of the given chaosresult
+func (c *ChaosMetricsCollector) collectResult(ch chan<- prometheus.Metric, result resultSnapshot) {
+	resultDetails := result.details
+	resultLabels := []string{resultDetails.Namespace, resultDetails.Name, resultDetails.ChaosEngineName, resultDetails.ChaosEngineContext, resultDetails.FaultName}
+	withLabels := func(labels ...string) []string {
+		return append(append([]string{}, resultLabels...), labels...)
+	}
+
+	sendMetric(ch, c.awaitedExperiments, prometheus.GaugeValue, resultDetails.AwaitedExperiments,
+		resultDetails.Namespace, resultDetails.Name, resultDetails.ChaosEngineName, resultDetails.ChaosEngineContext, resultDetails.WorkflowName, resultDetails.FaultName)
+	sendMetric(ch, c.passedExperiments, prometheus.GaugeValue, resultDetails.PassedExperiments, resultLabels...)
+	sendMetric(ch, c.failedExperiments, prometheus.GaugeValue, resultDetails.FailedExperiments, resultLabels...)
+	sendMetric(ch, c.stoppedExperiments, prometheus.GaugeValue, resultDetails.StoppedExperiments, resultLabels...)
+	sendMetric(ch, c.probeSuccessPercentage, prometheus.GaugeValue, resultDetails.ProbeSuccessPercentage, resultLabels...)
+
+	verdictValue := result.verdictValue
+	if strings.ToLower(resultDetails.Verdict) == "awaited" {
+		verdictValue = 0
+	}
+	sendMetric(ch, c.verdict, prometheus.GaugeValue, verdictValue,
+		resultDetails.Namespace, resultDetails.Name, resultDetails.ChaosEngineName, resultDetails.ChaosEngineContext, resultDetails.Verdict, fmt.Sprintf("%f", resultDetails.ProbeSuccessPercentage),
+		resultDetails.AppLabel, resultDetails.AppNs, resultDetails.AppKind, resultDetails.WorkflowName, resultDetails.FaultName)
+
+	sendMetric(ch, c.startTime, prometheus.GaugeValue, resultDetails.StartTime, resultLabels...)
+	sendMetric(ch, c.endTime, prometheus.GaugeValue, resultDetails.EndTime, resultLabels...)
+	sendMetric(ch, c.chaosInjectedTime, prometheus.GaugeValue, float64(resultDetails.InjectionTime), resultLabels...)
+	sendMetric(ch, c.totalDuration, prometheus.GaugeValue, resultDetails.TotalDuration, resultLabels...)
+	for timing, source := range map[string]string{
+		"start":          resultDetails.TimingSources.StartTime,
+		"end":            resultDetails.TimingSources.EndTime,
+		"chaos_injected": resultDetails.TimingSources.InjectionTime,
+	} {
+		if source != "" {
+			sendMetric(ch, c.timingSource, prometheus.GaugeValue, 1, withLabels(timing, source)...)
+		}
+	}
+
+	// the failure info is exported only if the verdict is failed
+	if isFailedVerdict(resultDetails.Verdict) {
+		sendMetric(ch, c.failureInfo, prometheus.GaugeValue, 1, withLabels(getErrorCodeLabel(resultDetails.ErrorCode), getFailureCategory(resultDetails.ErrorCode))...)
+	}
+	for _, probe := range resultDetails.ProbeStatuses {
+		sendMetric(ch, c.probeVerdict, prometheus.GaugeValue, 1, withLabels(probe.Name, probe.Type, probe.Mode, probe.Verdict)...)
+		sendMetric(ch, c.probeStatus, prometheus.GaugeValue, getProbeStatusValue(probe.Verdict), withLabels(probe.Name, probe.Type, probe.Mode)...)
+	}
+	for _, target := range resultDetails.Targets {
+		sendMetric(ch, c.targetInfo, prometheus.GaugeValue, 1, withLabels(target.Name, target.Kind, target.ChaosStatus)...)
+		sendMetric(ch, c.targetChaosStatus, prometheus.GaugeValue, getTargetChaosStatusValue(target.ChaosStatus), withLabels(target.Name, target.Kind)...)
+	}
+	for key, count := range result.transitions.counts {
+		sendMetric(ch, c.probeTransitions, prometheus.CounterValue, count,
+			resultDetails.Namespace, resultDetails.Name, string(resultDetails.UID), key.name, key.mode, key.verdict)
+	}
+	for key, timestamp := range result.transitions.lastTransitions {
+		sendMetric(ch, c.probeLastTransitionTimestamp, prometheus.GaugeValue, timestamp,
+			resultDetails.Namespace, resultDetails.Name, string(resultDetails.UID), key.name, key.mode)
+	}
+}
+
+// collectAggregates sends the aggregate metrics of all the chaosresults
+func (c *ChaosMetricsCollector) collectAggregates(ch chan<- prometheus.Metric, aggregates NamespacedScopeMetrics) {
+	descs, labels := c.clusterScoped, []string{}
+	if c.watchNamespace != "" {
+		descs, labels = c.namespaceScoped, []string{c.watchNamespace}
+	}
+	sendMetric(ch, descs.passedExperiments, prometheus.GaugeValue, aggregates.PassedExperiments, labels...)
+	sendMetric(ch, descs.failedExperiments, prometheus.GaugeValue, aggregates.FailedExperiments, labels...)
+	sendMetric(ch, descs.awaitedExperiments, prometheus.GaugeValue, aggregates.AwaitedExperiments, labels...)
+	sendMetric(ch, descs.stoppedExperiments, prometheus.GaugeValue, aggregates.StoppedExperiments, labels...)
+	sendMetric(ch, descs.experimentsRunCount, prometheus.GaugeValue, aggregates.ExperimentRunCount, labels...)
+	sendMetric(ch, descs.experimentsInstalledCount, prometheus.GaugeValue, aggregates.ExperimentsInstalledCount, labels...)
+}
+
+// sendMetric sends the metric with the given value and label values, the metric having invalid label values is skipped
+// so that it doesn't fail the complete scrape. It doesn't catch the duplicate series, hence the repeated probes, targets
+// and experiments are merged while deriving them from the chaosresults and the chaosengines
+func sendMetric(ch chan<- prometheus.Metric, desc *prometheus.Desc, valueType prometheus.ValueType, value float64, labelValues ...string) {
+	metric, err := prometheus.NewConstMetric(desc, valueType, value, labelValues...)
+	if err != nil {
+		log.Errorf("Unable to export the metric %s, err: %v", desc, err)
+		return
+	}
+	ch <- metric
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2019 LitmusChaos Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func FuzzChaosMetricsCollector(f *testing.F) {
+	f.Add("pod-delete", "litmus", "engine", "test", "pod-delete", "check-url", "Passed", "Fail", "nginx", 1.0, 100.0, 1700000000.0)
+	f.Add("", "", "", "", "", "", "", "", "", 0.0, 0.0, 0.0)
+	f.Add("\xff", "litmus", "engine\n", "\"", "pod-delete", "\x00", "Awaited", "Pass", "\xfe", -1.0, 1e308, -1e308)
+	f.Fuzz(func(t *testing.T, name, namespace, engineName, engineContext, faultName, probeName, probeVerdict, verdict, targetName string, passed, probeSuccessPercentage, startTime float64) {
+		resultDetails := ChaosResultDetails{
+			Name:                   name,
+			UID:                    "uid",
+			Namespace:              namespace,
+			PassedExperiments:      passed,
+			ProbeSuccessPercentage: probeSuccessPercentage,
+			StartTime:              startTime,
+			ChaosEngineName:        engineName,
+			ChaosEngineContext:     engineContext,
+			Verdict:                verdict,
+			FaultName:              faultName,
+			ProbeStatuses:          []ProbeDetails{{Name: probeName, Type: "httpProbe", Mode: "Continuous", Verdict: probeVerdict}},
+			Targets:                []TargetDetails{{Name: targetName, Kind: "pod", ChaosStatus: "injected"}},
+			TimingSources:          TimingSources{StartTime: TimingSourceEvents, EndTime: TimingSourceNone, InjectionTime: TimingSourceNone},
+		}
+		transitions := probeTransitions{}.next(resultDetails.ProbeStatuses, []ProbeDetails{{Name: probeName, Mode: "Continuous", Verdict: "Awaited"}}, time.Unix(0, 0))
+
+		collector := NewChaosMetricsCollector("")
+		collector.update(func(snapshot *metricsSnapshot) {
+			snapshot.results[namespace+"/"+name] = resultSnapshot{details: resultDetails, verdictValue: 1, transitions: transitions}
+			snapshot.aggregates = NamespacedScopeMetrics{PassedExperiments: passed}
+		})
+
+		// the pedantic registry fails the gathering if any collected metric is invalid or inconsistent with its description
+		registry := prometheus.NewPedanticRegistry()
+		if err := registry.Register(collector); err != nil {
+			t.Fatalf("unable to register the collector, err: %v", err)
+		}
+		if _, err := registry.Gather(); err != nil {
+			t.Errorf("invalid metrics collected, err: %v", err)
+		}
+		// the malformed chaosresult must not fail the scrape, the aggregates are always exported
+		if testutil.CollectAndCount(collector, "litmuschaos_cluster_scoped_experiments_installed_count") != 1 {
+			t.Error("expected aggregate metrics not found")
+		}
+	})
+}
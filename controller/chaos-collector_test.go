@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// resultMetricNames contains the names of the metrics exported per chaosresult
+var resultMetricNames = []string{
+	"litmuschaos_passed_experiments",
+	"litmuschaos_failed_experiments",
+	"litmuschaos_awaited_experiments",
+	"litmuschaos_stopped_experiments",
+	"litmuschaos_probe_success_percentage",
+	"litmuschaos_experiment_verdict",
+	"litmuschaos_experiment_failure_info",
+	"litmuschaos_probe_verdict",
+	"litmuschaos_probe_status",
+	"litmuschaos_probe_transitions_total",
+	"litmuschaos_probe_last_transition_timestamp",
+	"litmuschaos_experiment_target_info",
+	"litmuschaos_experiment_target_chaos_status",
+	"litmuschaos_experiment_start_time",
+	"litmuschaos_experiment_end_time",
+	"litmuschaos_experiment_chaos_injected_time",
+	"litmuschaos_experiment_total_duration",
+	"litmuschaos_experiment_timing_source",
+}
+
+func TestChaosMetricsCollector(t *testing.T) {
+	collector := NewChaosMetricsCollector("litmus")
+	// the descriptions are consistent with the collected metrics
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+
+	resultDetails := ChaosResultDetails{
+		Name:               "result",
+		Namespace:          "litmus",
+		UID:                "UID",
+		ChaosEngineName:    "engine",
+		ChaosEngineContext: "context",
+		FaultName:          "pod-delete",
+		Verdict:            "Fail",
+		ErrorCode:          "STATUS_CHECKS_ERROR",
+		FailedExperiments:  1,
+		ProbeStatuses: []ProbeDetails{
+			{Name: "check-frontend", Type: "httpProbe", Mode: "Continuous", Verdict: "Failed"},
+		},
+		Targets: []TargetDetails{
+			{Name: "nginx-1", Kind: "pod", ChaosStatus: "injected"},
+		},
+		TimingSources: TimingSources{StartTime: TimingSourceEvents, EndTime: TimingSourceNone, InjectionTime: TimingSourceCache},
+	}
+	transitions := probeTransitions{}.next(resultDetails.ProbeStatuses, []ProbeDetails{
+		{Name: "check-frontend", Type: "httpProbe", Mode: "Continuous", Verdict: "Awaited"},
+	}, testTime)
+	collector.update(func(snapshot *metricsSnapshot) {
+		snapshot.results["litmus/result"] = resultSnapshot{details: resultDetails, verdictValue: 1, transitions: transitions}
+		snapshot.aggregates = NamespacedScopeMetrics{FailedExperiments: 1, ExperimentRunCount: 1, ExperimentsInstalledCount: 1}
+	})
+	_, err := registry.Gather()
+	require.NoError(t, err)
+
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP litmuschaos_experiment_failure_info Error code and failure category of the failed experiments
+# TYPE litmuschaos_experiment_failure_info gauge
+litmuschaos_experiment_failure_info{chaosengine_context="context",chaosengine_name="engine",chaosresult_name="result",chaosresult_namespace="litmus",error_code="STATUS_CHECKS_ERROR",failure_category="status_checks",fault_name="pod-delete"} 1
+# HELP litmuschaos_probe_status Status of the individual probes, 1 for passed, 0 for failed and -1 for awaited or N/A
+# TYPE litmuschaos_probe_status gauge
+litmuschaos_probe_status{chaosengine_context="context",chaosengine_name="engine",chaosresult_name="result",chaosresult_namespace="litmus",fault_name="pod-delete",probe_mode="Continuous",probe_name="check-frontend",probe_type="httpProbe"} 0
+# HELP litmuschaos_experiment_target_chaos_status Chaos status of the individual targets, 0 for targeted, 1 for injected, 2 for reverted and -1 for unknown
+# TYPE litmuschaos_experiment_target_chaos_status gauge
+litmuschaos_experiment_target_chaos_status{chaosengine_context="context",chaosengine_name="engine",chaosresult_name="result",chaosresult_namespace="litmus",fault_name="pod-delete",target_kind="pod",target_name="nginx-1"} 1
+# HELP litmuschaos_probe_transitions_total Total number of the verdict transitions of the individual probes, labelled by the new verdict
+# TYPE litmuschaos_probe_transitions_total counter
+litmuschaos_probe_transitions_total{chaosresult_name="result",chaosresult_namespace="litmus",chaosresult_uid="UID",probe_mode="Continuous",probe_name="check-frontend",probe_verdict="Failed"} 1
+# HELP litmuschaos_namespace_scoped_failed_experiments Total number of failed experiments in watch namespace
+# TYPE litmuschaos_namespace_scoped_failed_experiments gauge
+litmuschaos_namespace_scoped_failed_experiments{chaosresult_namespace="litmus"} 1
+# HELP litmuschaos_experiment_timing_source Source of the experiment timings, labelled by the timing and its source
+# TYPE litmuschaos_experiment_timing_source gauge
+litmuschaos_experiment_timing_source{chaosengine_context="context",chaosengine_name="engine",chaosresult_name="result",chaosresult_namespace="litmus",fault_name="pod-delete",source="cache",timing="chaos_injected"} 1
+litmuschaos_experiment_timing_source{chaosengine_context="context",chaosengine_name="engine",chaosresult_name="result",chaosresult_namespace="litmus",fault_name="pod-delete",source="events",timing="start"} 1
+litmuschaos_experiment_timing_source{chaosengine_context="context",chaosengine_name="engine",chaosresult_name="result",chaosresult_namespace="litmus",fault_name="pod-delete",source="none",timing="end"} 1
+`), "litmuschaos_experiment_failure_info", "litmuschaos_probe_status", "litmuschaos_experiment_target_chaos_status",
+		"litmuschaos_probe_transitions_total", "litmuschaos_namespace_scoped_failed_experiments", "litmuschaos_experiment_timing_source"))
+	// the cluster scoped aggregates are not exported along with the namespace scoped aggregates
+	require.Zero(t, testutil.CollectAndCount(collector, "litmuschaos_cluster_scoped_failed_experiments"))
+
+	// the series with the outdated label values are replaced along with the snapshot
+	resultDetails.Verdict = "Pass"
+	resultDetails.ErrorCode = ""
+	resultDetails.ProbeStatuses = []ProbeDetails{{Name: "check-frontend", Type: "httpProbe", Mode: "Continuous", Verdict: "Passed"}}
+	resultDetails.Targets = []TargetDetails{{Name: "nginx-1", Kind: "pod", ChaosStatus: "reverted"}}
+	collector.update(func(snapshot *metricsSnapshot) {
+		snapshot.results["litmus/result"] = resultSnapshot{details: resultDetails, verdictValue: 1, transitions: transitions}
+	})
+	require.Zero(t, testutil.CollectAndCount(collector, "litmuschaos_experiment_failure_info"))
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP litmuschaos_experiment_verdict Verdict of the experiments
+# TYPE litmuschaos_experiment_verdict gauge
+litmuschaos_experiment_verdict{app_kind="",app_label="",app_namespace="",chaosengine_context="context",chaosengine_name="engine",chaosresult_name="result",chaosresult_namespace="litmus",chaosresult_verdict="Pass",fault_name="pod-delete",probe_success_percentage="0.000000",workflow_name=""} 1
+# HELP litmuschaos_probe_verdict Verdict of the individual probes
+# TYPE litmuschaos_probe_verdict gauge
+litmuschaos_probe_verdict{chaosengine_context="context",chaosengine_name="engine",chaosresult_name="result",chaosresult_namespace="litmus",fault_name="pod-delete",probe_mode="Continuous",probe_name="check-frontend",probe_type="httpProbe",probe_verdict="Passed"} 1
+# HELP litmuschaos_experiment_target_info Targets of the experiments along with their chaos status
+# TYPE litmuschaos_experiment_target_info gauge
+litmuschaos_experiment_target_info{chaosengine_context="context",chaosengine_name="engine",chaosresult_name="result",chaosresult_namespace="litmus",fault_name="pod-delete",target_chaos_status="reverted",target_kind="pod",target_name="nginx-1"} 1
+`), "litmuschaos_experiment_verdict", "litmuschaos_probe_verdict", "litmuschaos_experiment_target_info"))
+
+	// the awaited verdict is always exported with 0 value
+	resultDetails.Verdict = "Awaited"
+	collector.update(func(snapshot *metricsSnapshot) {
+		snapshot.results["litmus/result"] = resultSnapshot{details: resultDetails, verdictValue: 1}
+	})
+	metricFamilies, err := registry.Gather()
+	require.NoError(t, err)
+	for _, metricFamily := range metricFamilies {
+		if metricFamily.GetName() == "litmuschaos_experiment_verdict" {
+			require.Len(t, metricFamily.GetMetric(), 1)
+			require.Zero(t, metricFamily.GetMetric()[0].GetGauge().GetValue())
+		}
+	}
+
+	// all the metrics of the deleted chaosresult are removed along with it
+	collector.update(func(snapshot *metricsSnapshot) {
+		delete(snapshot.results, "litmus/result")
+	})
+	require.Zero(t, testutil.CollectAndCount(collector, resultMetricNames...))
+	require.Equal(t, 6, testutil.CollectAndCount(collector))
+}
+
+func TestChaosMetricsCollectorSnapshotIsolation(t *testing.T) {
+	collector := NewChaosMetricsCollector("")
+	collector.update(func(snapshot *metricsSnapshot) {
+		snapshot.results["litmus/result-1"] = resultSnapshot{details: ChaosResultDetails{Name: "result-1", Namespace: "litmus"}}
+	})
+	loaded := collector.load()
+
+	// the loaded snapshot is not modified by the later updates
+	collector.update(func(snapshot *metricsSnapshot) {
+		snapshot.results["litmus/result-2"] = resultSnapshot{details: ChaosResultDetails{Name: "result-2", Namespace: "litmus"}}
+		delete(snapshot.results, "litmus/result-1")
+	})
+	require.Len(t, loaded.results, 1)
+	require.Contains(t, loaded.results, "litmus/result-1")
+	require.Len(t, collector.load().results, 1)
+	require.Contains(t, collector.load().results, "litmus/result-2")
+}
@@ -0,0 +1,243 @@
+/*
+Copyright 2019 LitmusChaos Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"github.com/pkg/errors"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/log"
+)
+
+const (
+	// cloudWatchMaxBatchSize is the maximum number of datums accepted by a PutMetricData call
+	cloudWatchMaxBatchSize = 1000
+	// cloudWatchMaxRetries is the default number of retries of a failed PutMetricData call
+	cloudWatchMaxRetries = 3
+	// cloudWatchRetryBackoff is the default initial backoff between the retries, it is doubled after every retry
+	cloudWatchRetryBackoff = time.Second
+	// cloudWatchQueueCapacity is the maximum number of the batches pending to be sent
+	cloudWatchQueueCapacity = 100
+)
+
+// CloudWatchSink publishes the chaos metrics to the aws cloudwatch service
+// The datums are buffered and queued in batches, whenever the batch is full or the sink is flushed.
+// The queued batches are sent by a separate goroutine, so that the retries don't block the metrics collection
+type CloudWatchSink struct {
+	AWSConfig AWSConfig
+	// Client is the long-lived cloudwatch client shared by all the calls
+	Client cloudwatchiface.CloudWatchAPI
+	// MaxRetries is the number of retries of a failed PutMetricData call
+	MaxRetries int
+	// RetryBackoff is the initial backoff between the retries
+	RetryBackoff time.Duration
+
+	mu     sync.Mutex
+	datums []*cloudwatch.MetricDatum
+	// batches contains the batches pending to be sent
+	batches chan []*cloudwatch.MetricDatum
+	// sender starts the goroutine sending the queued batches, on the first queued batch
+	sender sync.Once
+}
+
+// NewCloudWatchSinkFromEnv creates the cloudwatch sink from the aws ENVs
+// AWS_CLOUDWATCH_ENDPOINT overrides the cloudwatch endpoint, e.g, for a local stand-in
+func NewCloudWatchSinkFromEnv() (Sink, error) {
+	awsConfig := getAWSConfigFromEnv()
+	if !awsConfig.isValid() {
+		return nil, errors.Errorf("You must supply AWS_CLOUDWATCH_METRIC_NAMESPACE, CLUSTER_NAME and APP_NAME ENVs")
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to create the aws session")
+	}
+	// the retries are handled by the sink, so that the backoff spans the complete batch
+	config := aws.NewConfig().WithMaxRetries(0)
+	if endpoint := os.Getenv("AWS_CLOUDWATCH_ENDPOINT"); endpoint != "" {
+		config = config.WithEndpoint(endpoint)
+	}
+	return NewCloudWatchSink(awsConfig, cloudwatch.New(sess, config)), nil
+}
+
+// NewCloudWatchSink creates the cloudwatch sink with the given client
+func NewCloudWatchSink(awsConfig AWSConfig, client cloudwatchiface.CloudWatchAPI) *CloudWatchSink {
+	return &CloudWatchSink{
+		AWSConfig:    awsConfig,
+		Client:       client,
+		MaxRetries:   cloudWatchMaxRetries,
+		RetryBackoff: cloudWatchRetryBackoff,
+		batches:      make(chan []*cloudwatch.MetricDatum, cloudWatchQueueCapacity),
+	}
+}
+
+// getAWSConfigFromEnv derives all the data required for aws configuration
+func getAWSConfigFromEnv() AWSConfig {
+	return AWSConfig{
+		Namespace:   os.Getenv("AWS_CLOUDWATCH_METRIC_NAMESPACE"),
+		ClusterName: os.Getenv("CLUSTER_NAME"),
+		Service:     os.Getenv("APP_NAME"),
+	}
+}
+
+// isValid checks whether all the aws configuration details are provided
+func (awsConfig AWSConfig) isValid() bool {
+	return awsConfig.Namespace != "" && awsConfig.ClusterName != "" && awsConfig.Service != ""
+}
+
+// PublishResult buffers the aws metrics for the given chaosresult
+func (sink *CloudWatchSink) PublishResult(resultDetails ChaosResultDetails) error {
+	dimensions := sink.dimensions(
+		"ResultName", resultDetails.Name,
+		"ResultNamespace", resultDetails.Namespace,
+		"FaultName", resultDetails.FaultName,
+	)
+	return sink.add(
+		newMetricDatum("chaosresult_passed_experiments", resultDetails.PassedExperiments, dimensions),
+		newMetricDatum("chaosresult_failed_experiments", resultDetails.FailedExperiments, dimensions),
+		newMetricDatum("chaosresult_awaited_experiments", resultDetails.AwaitedExperiments, dimensions),
+		newMetricDatum("chaosresult_stopped_experiments", resultDetails.StoppedExperiments, dimensions),
+		newMetricDatum("chaosresult_probe_success_percentage", resultDetails.ProbeSuccessPercentage, dimensions),
+		newMetricDatum("chaosresult_start_time", resultDetails.StartTime, dimensions),
+		newMetricDatum("chaosresult_end_time", resultDetails.EndTime, dimensions),
+		newMetricDatum("chaosresult_inject_time", float64(resultDetails.InjectionTime), dimensions),
+		newMetricDatum("chaosresult_total_duration", resultDetails.TotalDuration, dimensions),
+	)
+}
+
+// PublishAggregate buffers the aws metrics for all chaosresults
+func (sink *CloudWatchSink) PublishAggregate(namespacedScopeMetrics NamespacedScopeMetrics) error {
+	dimensions := sink.dimensions()
+	return sink.add(
+		newMetricDatum("total_passed_experiments", namespacedScopeMetrics.PassedExperiments, dimensions),
+		newMetricDatum("total_failed_experiments", namespacedScopeMetrics.FailedExperiments, dimensions),
+		newMetricDatum("total_awaited_experiments", namespacedScopeMetrics.AwaitedExperiments, dimensions),
+		newMetricDatum("total_stopped_experiments", namespacedScopeMetrics.StoppedExperiments, dimensions),
+		newMetricDatum("experiments_run_count", namespacedScopeMetrics.ExperimentRunCount, dimensions),
+		newMetricDatum("experiments_installed_count", namespacedScopeMetrics.ExperimentsInstalledCount, dimensions),
+	)
+}
+
+// Flush queues all the buffered datums
+func (sink *CloudWatchSink) Flush() error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	return sink.enqueue(len(sink.datums))
+}
+
+// add buffers the given datums and queues the full batches
+func (sink *CloudWatchSink) add(datums ...*cloudwatch.MetricDatum) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	sink.datums = append(sink.datums, datums...)
+	return sink.enqueue(len(sink.datums) - len(sink.datums)%cloudWatchMaxBatchSize)
+}
+
+// enqueue queues the first count buffered datums in batches, the batches are dropped
+// once the queue is full, so that a broken endpoint doesn't grow the buffer
+func (sink *CloudWatchSink) enqueue(count int) error {
+	if count > 0 {
+		sink.sender.Do(func() { go sink.run() })
+	}
+	var dropped int
+	for count > 0 {
+		size := count
+		if size > cloudWatchMaxBatchSize {
+			size = cloudWatchMaxBatchSize
+		}
+		select {
+		case sink.batches <- sink.datums[:size:size]:
+		default:
+			dropped += size
+		}
+		sink.datums = sink.datums[size:]
+		count -= size
+	}
+	if len(sink.datums) == 0 {
+		sink.datums = nil
+	}
+	if dropped != 0 {
+		return errors.Errorf("cloudwatch queue is full, dropping %d metrics", dropped)
+	}
+	return nil
+}
+
+// run sends the queued batches, the failed batches are dropped after all the retries
+func (sink *CloudWatchSink) run() {
+	for datums := range sink.batches {
+		if err := sink.putMetricData(datums); err != nil {
+			log.Errorf("Unable to publish the metrics to cloudwatch, err: %v", err)
+		}
+	}
+}
+
+// putMetricData put the metrics data in cloudwatch service, it retries the failed call with the exponential backoff
+func (sink *CloudWatchSink) putMetricData(datums []*cloudwatch.MetricDatum) error {
+	input := &cloudwatch.PutMetricDataInput{
+		Namespace:  aws.String(sink.AWSConfig.Namespace),
+		MetricData: datums,
+	}
+	backoff := sink.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		log.Infof("Putting %d AWS metrics: Namespace %v", len(datums), sink.AWSConfig.Namespace)
+		_, err := sink.Client.PutMetricData(input)
+		if err == nil {
+			return nil
+		}
+		if attempt >= sink.MaxRetries {
+			return errors.Wrapf(err, "unable to put %d metrics to cloudwatch after %d retries", len(datums), attempt)
+		}
+		log.Warnf("Unable to put the metrics to cloudwatch, retrying in %v, err: %v", backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// dimensions returns the cluster and service dimensions, followed by the given name value pairs
+// the dimensions with empty values are skipped, as cloudwatch rejects them
+func (sink *CloudWatchSink) dimensions(nameValues ...string) []*cloudwatch.Dimension {
+	nameValues = append([]string{"ClusterName", sink.AWSConfig.ClusterName, "Service", sink.AWSConfig.Service}, nameValues...)
+	var dimensions []*cloudwatch.Dimension
+	for i := 0; i+1 < len(nameValues); i += 2 {
+		if nameValues[i+1] == "" {
+			continue
+		}
+		dimensions = append(dimensions, &cloudwatch.Dimension{
+			Name:  aws.String(nameValues[i]),
+			Value: aws.String(nameValues[i+1]),
+		})
+	}
+	return dimensions
+}
+
+// newMetricDatum creates the datum of the given metric
+func newMetricDatum(metricName string, value float64, dimensions []*cloudwatch.Dimension) *cloudwatch.MetricDatum {
+	return &cloudwatch.MetricDatum{
+		MetricName: aws.String(metricName),
+		Unit:       aws.String(cloudwatch.StandardUnitCount),
+		Value:      aws.Float64(value),
+		Dimensions: dimensions,
+	}
+}
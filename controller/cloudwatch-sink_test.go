@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/stretchr/testify/require"
+)
+
+// cloudWatchStandIn is a local http stand-in for the cloudwatch endpoint
+type cloudWatchStandIn struct {
+	mu sync.Mutex
+	// failures is the number of the upcoming calls which fail
+	failures int
+	// calls contains the number of datums received by every successful call
+	calls []int
+	// dimensions contains the dimensions of every received datum, keyed by the metric name
+	dimensions map[string]map[string]string
+}
+
+func (s *cloudWatchStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := r.ParseForm(); err != nil || r.Form.Get("Action") != "PutMetricData" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `<ErrorResponse><Error><Code>InternalFailure</Code><Message>fake error</Message></Error><RequestId>1</RequestId></ErrorResponse>`)
+		return
+	}
+	datums := 0
+	for i := 1; r.Form.Get(fmt.Sprintf("MetricData.member.%d.MetricName", i)) != ""; i++ {
+		datums++
+		dimensions := map[string]string{}
+		for j := 1; r.Form.Get(fmt.Sprintf("MetricData.member.%d.Dimensions.member.%d.Name", i, j)) != ""; j++ {
+			dimensions[r.Form.Get(fmt.Sprintf("MetricData.member.%d.Dimensions.member.%d.Name", i, j))] =
+				r.Form.Get(fmt.Sprintf("MetricData.member.%d.Dimensions.member.%d.Value", i, j))
+		}
+		s.dimensions[r.Form.Get(fmt.Sprintf("MetricData.member.%d.MetricName", i))] = dimensions
+	}
+	s.calls = append(s.calls, datums)
+	fmt.Fprint(w, `<PutMetricDataResponse xmlns="http://monitoring.amazonaws.com/doc/2010-08-01/"><ResponseMetadata><RequestId>1</RequestId></ResponseMetadata></PutMetricDataResponse>`)
+}
+
+// newTestCloudWatchSink creates the cloudwatch sink pointing to the given stand-in
+func newTestCloudWatchSink(t *testing.T, standIn *cloudWatchStandIn) *CloudWatchSink {
+	server := httptest.NewServer(standIn)
+	t.Cleanup(server.Close)
+	sess, err := session.NewSession(aws.NewConfig().
+		WithRegion("us-east-1").
+		WithEndpoint(server.URL).
+		WithMaxRetries(0).
+		WithCredentials(credentials.NewStaticCredentials("id", "secret", "")))
+	require.NoError(t, err)
+	sink := NewCloudWatchSink(AWSConfig{Namespace: "litmus", ClusterName: "cluster", Service: "app"}, cloudwatch.New(sess))
+	sink.RetryBackoff = time.Millisecond
+	return sink
+}
+
+func TestCloudWatchSink(t *testing.T) {
+	tests := []struct {
+		name          string
+		results       int
+		failures      int
+		expectedCalls []int
+		isErr         bool
+	}{
+		{
+			name:          "success: result and aggregate datums are sent in a single call",
+			results:       1,
+			expectedCalls: []int{15},
+		},
+		{
+			name:          "success: datums are sent in batches of 1000",
+			results:       200,
+			expectedCalls: []int{1000, 800 + 6},
+		},
+		{
+			name:          "success: failed call is retried",
+			results:       1,
+			failures:      2,
+			expectedCalls: []int{15},
+		},
+		{
+			name:     "failure: retries are exhausted",
+			results:  1,
+			failures: 4,
+			isErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			standIn := &cloudWatchStandIn{failures: tt.failures, dimensions: map[string]map[string]string{}}
+			sink := newTestCloudWatchSink(t, standIn)
+
+			for i := 0; i < tt.results; i++ {
+				require.NoError(t, sink.PublishResult(ChaosResultDetails{
+					Name:      fmt.Sprintf("chaosresult-%d", i),
+					Namespace: "litmus",
+					FaultName: "pod-delete",
+				}))
+			}
+			require.NoError(t, sink.PublishAggregate(NamespacedScopeMetrics{}))
+			require.NoError(t, sink.Flush())
+			require.Empty(t, sink.datums)
+			// the batches are sent by the sender goroutine, it is done once all the failures are consumed
+			// and all the expected calls are received by the stand-in
+			require.Eventually(t, func() bool {
+				standIn.mu.Lock()
+				defer standIn.mu.Unlock()
+				return standIn.failures == 0 && len(standIn.calls) == len(tt.expectedCalls)
+			}, 5*time.Second, 10*time.Millisecond)
+			standIn.mu.Lock()
+			defer standIn.mu.Unlock()
+			if tt.isErr {
+				require.Empty(t, standIn.calls)
+				return
+			}
+			require.Equal(t, tt.expectedCalls, standIn.calls)
+			require.Empty(t, sink.datums)
+
+			require.Equal(t, map[string]string{
+				"ClusterName":     "cluster",
+				"Service":         "app",
+				"ResultName":      fmt.Sprintf("chaosresult-%d", tt.results-1),
+				"ResultNamespace": "litmus",
+				"FaultName":       "pod-delete",
+			}, standIn.dimensions["chaosresult_passed_experiments"])
+			require.Equal(t, map[string]string{
+				"ClusterName": "cluster",
+				"Service":     "app",
+			}, standIn.dimensions["total_passed_experiments"])
+		})
+	}
+}
+
+func TestCloudWatchSinkRetriesDontBlock(t *testing.T) {
+	standIn := &cloudWatchStandIn{failures: 1, dimensions: map[string]map[string]string{}}
+	sink := newTestCloudWatchSink(t, standIn)
+	sink.RetryBackoff = time.Hour
+
+	// the failed batch is retried by the sender goroutine, while the publishing and flushing return immediately
+	start := time.Now()
+	require.NoError(t, sink.PublishResult(ChaosResultDetails{Name: "chaosresult-1", Namespace: "litmus"}))
+	require.NoError(t, sink.Flush())
+	require.Eventually(t, func() bool {
+		standIn.mu.Lock()
+		defer standIn.mu.Unlock()
+		return standIn.failures == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, sink.Flush())
+	require.Less(t, time.Since(start), time.Minute)
+	require.Empty(t, standIn.calls)
+}
+
+func TestCloudWatchSinkQueueFull(t *testing.T) {
+	sink := NewCloudWatchSink(AWSConfig{Namespace: "litmus", ClusterName: "cluster", Service: "app"}, nil)
+	// the sender goroutine is not started, so that the queued batches aren't sent
+	sink.sender.Do(func() {})
+	for i := 0; i < cloudWatchQueueCapacity; i++ {
+		require.NoError(t, sink.PublishAggregate(NamespacedScopeMetrics{}))
+		require.NoError(t, sink.Flush())
+	}
+	require.NoError(t, sink.PublishAggregate(NamespacedScopeMetrics{}))
+	require.Error(t, sink.Flush())
+	require.Empty(t, sink.datums)
+}
+
+func TestCloudWatchSinkDimensions(t *testing.T) {
+	sink := NewCloudWatchSink(AWSConfig{Namespace: "litmus", ClusterName: "cluster", Service: "app"}, nil)
+	var names []string
+	for _, dimension := range sink.dimensions("ResultName", "chaosresult-1", "FaultName", "") {
+		names = append(names, *dimension.Name)
+	}
+	// the dimensions with empty values are skipped
+	require.Equal(t, "ClusterName,Service,ResultName", strings.Join(names, ","))
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/litmuschaos/chaos-exporter/pkg/log"
 	"github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
 	litmuschaosv1alpha1 "github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
@@ -17,48 +18,47 @@ import (
 
 //go:generate mockgen -destination=mocks/mock_collect-data.go -package=mocks github.com/litmuschaos/chaos-exporter/controller ResultCollector
 
-// ResultCollector interface for the both functions GetResultList and getExperimentMetricsFromResult
+// ResultCollector interface for the both functions GetResult and getExperimentMetricsFromResult
 type ResultCollector interface {
-	GetResultList(clients clients.ClientSets, chaosNamespace string, monitoringEnabled *MonitoringEnabled) ([]*v1alpha1.ChaosResult, error)
+	GetResult(clients clients.ClientSets, namespace, name string) (*v1alpha1.ChaosResult, error)
 	GetExperimentMetricsFromResult(chaosResult *litmuschaosv1alpha1.ChaosResult, clients clients.ClientSets) (bool, error)
 	SetResultDetails()
 	GetResultDetails() ChaosResultDetails
 }
 type ResultDetails struct {
 	resultDetails ChaosResultDetails
+	state         *StateStore
 }
 
-// GetResultList return the result list correspond to the monitoring enabled chaosengine
-func (r *ResultDetails) GetResultList(clients clients.ClientSets, chaosNamespace string, monitoringEnabled *MonitoringEnabled) ([]*v1alpha1.ChaosResult, error) {
+// NewResultDetails creates the ResultDetails which stores the result data inside the given state store
+// the state store is owned by the MetricesCollecter, it must be the same instance as MetricesCollecter.State
+func NewResultDetails(state *StateStore) *ResultDetails {
+	return &ResultDetails{
+		state: state,
+	}
+}
 
-	chaosResultList, err := clients.ResultInformer.ChaosResults(chaosNamespace).List(labels.Everything())
+// GetResult return the chaosresult with the given namespace and name, nil if the chaosresult doesn't exist
+func (r *ResultDetails) GetResult(clients clients.ClientSets, namespace, name string) (*v1alpha1.ChaosResult, error) {
+	chaosResult, err := clients.ResultInformer.ChaosResults(namespace).Get(name)
 	if err != nil {
-		return nil, err
-	}
-	// waiting until any chaosresult found
-	if len(chaosResultList) == 0 {
-		if monitoringEnabled.IsChaosResultsAvailable {
-			monitoringEnabled.IsChaosResultsAvailable = false
-			log.Warnf("No chaosresult found!")
-			log.Info("[Wait]: Waiting for the chaosresult ... ")
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
 		}
-		return nil, nil
-	}
-
-	if !monitoringEnabled.IsChaosResultsAvailable {
-		log.Info("[Wait]: Cheers! Wait is over, found desired chaosresult")
-		monitoringEnabled.IsChaosResultsAvailable = true
+		return nil, err
 	}
-
-	return chaosResultList, nil
+	return chaosResult, nil
 }
 
 // GetExperimentMetricsFromResult derive all the metrics data from the chaosresult and set into resultDetails struct
 func (r *ResultDetails) GetExperimentMetricsFromResult(chaosResult *litmuschaosv1alpha1.ChaosResult, clients clients.ClientSets) (bool, error) {
+	if r.state == nil {
+		return false, errors.New("state store is not provided, the ResultDetails must be created with NewResultDetails")
+	}
 	verdict := strings.ToLower(string(chaosResult.Status.ExperimentStatus.Verdict))
 	probeSuccesPercentage, err := getProbeSuccessPercentage(chaosResult)
 	if err != nil {
-		return false, err
+		return false, &ResultError{Reason: ResultErrorParse, Err: errors.Wrap(err, "unable to parse the probe success percentage")}
 	}
 	engine, err := clients.EngineInformer.ChaosEngines(chaosResult.Namespace).Get(chaosResult.Spec.EngineName)
 	if err != nil {
@@ -70,11 +70,18 @@ func (r *ResultDetails) GetExperimentMetricsFromResult(chaosResult *litmuschaosv
 		return false, err
 	}
 
+	// deriving the fault name of the experiment correspond to the chaosresult
+	faultName, err := getFaultName(engine, chaosResult)
+	if err != nil {
+		return false, &ResultError{Reason: ResultErrorEngineWithoutExperiments, Err: err}
+	}
+
 	// deriving all the events present inside specific chaosengine
 	events, err := getEventsForSpecificInvolvedResource(clients, engine.UID, chaosResult.Namespace)
 	if err != nil {
-		return false, err
+		return false, &ResultError{Reason: ResultErrorEvents, Err: errors.Wrap(err, "unable to list the events")}
 	}
+	cachedTimings := r.state.Timings(string(chaosResult.UID))
 	// setting all the values inside resultdetails struct
 	r.resultDetails.setName(chaosResult.Name).
 		setUID(chaosResult.UID).
@@ -85,21 +92,27 @@ func (r *ResultDetails) GetExperimentMetricsFromResult(chaosResult *litmuschaosv
 		setEndTime(events).
 		setChaosInjectTime(events).
 		setChaosEngineName(chaosResult.Spec.EngineName).
+		setChaosEngineUID(engine.UID).
 		setChaosEngineContext(engine.Labels[EngineContext]).
 		setWorkflowName(engine.Labels[WorkFlowName]).
 		setAppLabel(engine.Spec.Appinfo.Applabel).
 		setAppNs(engine.Spec.Appinfo.Appns).
 		setAppKind(engine.Spec.Appinfo.AppKind).
-		setTotalDuration().
 		setVerdictCount(verdict, chaosResult).
-		setFaultName(engine.Spec.Experiments[0].Name).
-		setResultData()
+		setFaultName(faultName).
+		setProbeStatuses(getProbeDetails(chaosResult)).
+		setTargets(getTargetDetails(chaosResult)).
+		setErrorCode(getErrorCode(chaosResult)).
+		setTimingFallbacks(cachedTimings, engine, chaosResult).
+		setTotalDuration()
+	// caching the timings derived from the events, so that those are available once the events are expired
+	r.state.SetTimings(string(chaosResult.UID), r.resultDetails.getObservedTimings(cachedTimings))
 
 	// it won't export/override the metrics if chaosengine is in completed state and
 	// experiment's final verdict[passed,failed,stopped] is already exported/overridden
 	// and 'litmuschaos_experiment_verdict' metric was reset to 0
 	if engine.Status.EngineStatus == v1alpha1.EngineStatusCompleted {
-		result, ok := matchVerdict[string(r.resultDetails.UID)]
+		result, ok := r.state.Verdict(string(r.resultDetails.UID))
 		if !ok || (ok && result.Verdict == r.resultDetails.Verdict && result.VerdictReset) {
 			return true, nil
 		}
@@ -112,17 +125,13 @@ func (r *ResultDetails) SetResultDetails() {
 	r.resultDetails.PassedExperiments = 0
 	r.resultDetails.AwaitedExperiments = 0
 	r.resultDetails.FailedExperiments = 0
+	r.resultDetails.StoppedExperiments = 0
 }
 
 func (r *ResultDetails) GetResultDetails() ChaosResultDetails {
 	return r.resultDetails
 }
 
-// initialiseResult create the new instance of the ChaosResultDetails struct
-func initialiseResult() *ChaosResultDetails {
-	return &ChaosResultDetails{}
-}
-
 // setName sets name inside resultDetails struct
 func (resultDetails *ChaosResultDetails) setName(name string) *ChaosResultDetails {
 	resultDetails.Name = name
@@ -155,8 +164,13 @@ func (resultDetails *ChaosResultDetails) setVerdictCount(verdict string, chaosRe
 	case "awaited":
 		resultDetails.AwaitedExperiments++
 	}
+	// the chaosresult doesn't contain the history until the first run is recorded
+	if chaosResult.Status.History == nil {
+		return resultDetails
+	}
 	resultDetails.PassedExperiments = float64(chaosResult.Status.History.PassedRuns)
 	resultDetails.FailedExperiments = float64(chaosResult.Status.History.FailedRuns)
+	resultDetails.StoppedExperiments = float64(chaosResult.Status.History.StoppedRuns)
 	return resultDetails
 }
 
@@ -172,6 +186,12 @@ func (resultDetails *ChaosResultDetails) setChaosEngineName(chaosEngineName stri
 	return resultDetails
 }
 
+// setChaosEngineUID sets the uid of the chaosengine
+func (resultDetails *ChaosResultDetails) setChaosEngineUID(chaosEngineUID clientTypes.UID) *ChaosResultDetails {
+	resultDetails.ChaosEngineUID = chaosEngineUID
+	return resultDetails
+}
+
 // setAppLabel sets the target application labels inside resultDetails struct
 func (resultDetails *ChaosResultDetails) setAppLabel(appLabel string) *ChaosResultDetails {
 	resultDetails.AppLabel = appLabel
@@ -208,6 +228,24 @@ func (resultDetails *ChaosResultDetails) setFaultName(faultName string) *ChaosRe
 	return resultDetails
 }
 
+// setProbeStatuses sets the individual probe statuses inside resultDetails struct
+func (resultDetails *ChaosResultDetails) setProbeStatuses(probeStatuses []ProbeDetails) *ChaosResultDetails {
+	resultDetails.ProbeStatuses = probeStatuses
+	return resultDetails
+}
+
+// setTargets sets the individual target statuses inside resultDetails struct
+func (resultDetails *ChaosResultDetails) setTargets(targets []TargetDetails) *ChaosResultDetails {
+	resultDetails.Targets = targets
+	return resultDetails
+}
+
+// setErrorCode sets the error code of the failed experiment inside resultDetails struct
+func (resultDetails *ChaosResultDetails) setErrorCode(errorCode string) *ChaosResultDetails {
+	resultDetails.ErrorCode = errorCode
+	return resultDetails
+}
+
 // setStartTime sets start time of experiment run
 func (resultDetails *ChaosResultDetails) setStartTime(events corev1.EventList) *ChaosResultDetails {
 	startTime := int64(0)
@@ -218,6 +256,7 @@ func (resultDetails *ChaosResultDetails) setStartTime(events corev1.EventList) *
 		}
 	}
 	resultDetails.StartTime = float64(startTime)
+	resultDetails.TimingSources.StartTime = getEventTimingSource(startTime)
 	return resultDetails
 }
 
@@ -230,6 +269,7 @@ func (resultDetails *ChaosResultDetails) setEndTime(events corev1.EventList) *Ch
 		}
 	}
 	resultDetails.EndTime = float64(endTime)
+	resultDetails.TimingSources.EndTime = getEventTimingSource(endTime)
 	return resultDetails
 }
 
@@ -242,9 +282,18 @@ func (resultDetails *ChaosResultDetails) setChaosInjectTime(events corev1.EventL
 		}
 	}
 	resultDetails.InjectionTime = chaosInjectTime
+	resultDetails.TimingSources.InjectionTime = getEventTimingSource(chaosInjectTime)
 	return resultDetails
 }
 
+// getEventTimingSource returns the source of the timing derived from the chaosengine events
+func getEventTimingSource(timing int64) string {
+	if timing == 0 {
+		return TimingSourceNone
+	}
+	return TimingSourceEvents
+}
+
 // setTotalDuration sets total chaos duration for the experiment run
 func (resultDetails *ChaosResultDetails) setTotalDuration() *ChaosResultDetails {
 	resultDetails.TotalDuration = math.Max(0, resultDetails.EndTime-resultDetails.StartTime)
@@ -263,10 +312,124 @@ func getProbeSuccessPercentage(chaosResult *litmuschaosv1alpha1.ChaosResult) (fl
 	return probeSuccesPercentage, nil
 }
 
+// getFaultName derive the fault name of the experiment correspond to the given chaosresult
+// It matches the chaosresult with the experiments listed inside the chaosengine status,
+// falls back to the experiments listed inside the chaosengine spec and then to the chaosresult spec
+// It returns an empty fault name, if the chaosresult doesn't match any experiment of the chaosengine
+func getFaultName(engine *litmuschaosv1alpha1.ChaosEngine, chaosResult *litmuschaosv1alpha1.ChaosResult) (string, error) {
+	if len(engine.Spec.Experiments) == 0 && len(engine.Status.Experiments) == 0 {
+		return "", &EngineWithoutExperimentsError{
+			EngineName: engine.Name,
+			Namespace:  engine.Namespace,
+		}
+	}
+
+	for _, experiment := range engine.Status.Experiments {
+		if isResultOfExperiment(chaosResult, engine.Name, experiment.Name) {
+			return experiment.Name, nil
+		}
+	}
+	for _, experiment := range engine.Spec.Experiments {
+		if isResultOfExperiment(chaosResult, engine.Name, experiment.Name) {
+			return experiment.Name, nil
+		}
+	}
+
+	if chaosResult.Spec.ExperimentName != "" {
+		return chaosResult.Spec.ExperimentName, nil
+	}
+	log.Warnf("chaosresult %s/%s doesn't match any experiment of the chaosengine %s, exporting it without the fault name", chaosResult.Namespace, chaosResult.Name, engine.Name)
+	return "", nil
+}
+
+// isResultOfExperiment checks whether the chaosresult belongs to the given experiment
+// the chaosresult is named as <engine-name>-<experiment-name> by the chaos-runner
+func isResultOfExperiment(chaosResult *litmuschaosv1alpha1.ChaosResult, engineName, experimentName string) bool {
+	return chaosResult.Spec.ExperimentName == experimentName || chaosResult.Name == engineName+"-"+experimentName
+}
+
+// getProbeDetails derive the individual probe statuses from the chaosresult
+// the repeated probes are merged by their name and mode, keeping the last status, as the duplicate series fail the complete scrape
+func getProbeDetails(chaosResult *litmuschaosv1alpha1.ChaosResult) []ProbeDetails {
+	var probeDetails []ProbeDetails
+	indexes := map[probeKey]int{}
+	for _, probe := range chaosResult.Status.ProbeStatuses {
+		key := probeKey{name: probe.Name, mode: probe.Mode}
+		if i, ok := indexes[key]; ok {
+			probeDetails[i].Type, probeDetails[i].Verdict = probe.Type, string(probe.Status.Verdict)
+			continue
+		}
+		indexes[key] = len(probeDetails)
+		probeDetails = append(probeDetails, ProbeDetails{
+			Name:    probe.Name,
+			Type:    probe.Type,
+			Mode:    probe.Mode,
+			Verdict: string(probe.Status.Verdict),
+		})
+	}
+	return probeDetails
+}
+
+// getErrorCode derive the error code of the failed experiment from the chaosresult
+// it returns empty error code if the verdict is not failed
+func getErrorCode(chaosResult *litmuschaosv1alpha1.ChaosResult) string {
+	if chaosResult.Status.ExperimentStatus.Verdict != litmuschaosv1alpha1.ResultVerdictFailed || chaosResult.Status.ExperimentStatus.ErrorOutput == nil {
+		return ""
+	}
+	return chaosResult.Status.ExperimentStatus.ErrorOutput.ErrorCode
+}
+
+// getTargetDetails derive the individual target statuses from the chaosresult history
+// the repeated targets are merged by their name and kind, keeping the last chaos status, as the duplicate
+// series fail the complete scrape. At most TARGET_METRICS_LIMIT targets are derived to bound the cardinality
+func getTargetDetails(chaosResult *litmuschaosv1alpha1.ChaosResult) []TargetDetails {
+	if chaosResult.Status.History == nil {
+		return nil
+	}
+	limit, err := strconv.Atoi(getEnv("TARGET_METRICS_LIMIT", "50"))
+	if err != nil || limit < 0 {
+		limit = 50
+	}
+	var targetDetails []TargetDetails
+	indexes := map[TargetDetails]int{}
+	for _, target := range chaosResult.Status.History.Targets {
+		key := TargetDetails{Name: target.Name, Kind: target.Kind}
+		if i, ok := indexes[key]; ok {
+			targetDetails[i].ChaosStatus = target.ChaosStatus
+			continue
+		}
+		indexes[key] = len(targetDetails)
+		targetDetails = append(targetDetails, TargetDetails{
+			Name:        target.Name,
+			Kind:        target.Kind,
+			ChaosStatus: target.ChaosStatus,
+		})
+	}
+	if len(targetDetails) > limit {
+		log.Warnf("chaosresult %s/%s contains %d targets, exporting only the first %d targets", chaosResult.Namespace, chaosResult.Name, len(targetDetails), limit)
+		targetDetails = targetDetails[:limit]
+	}
+	return targetDetails
+}
+
 // getEventsForSpecificInvolvedResource derive all the events correspond to the specific resource
-func getEventsForSpecificInvolvedResource(clients clients.ClientSets, resourceUID clientTypes.UID, chaosNamespace string) (corev1.EventList, error) {
+// the events are looked up by the involved object uid index, if the events informer is indexed
+func getEventsForSpecificInvolvedResource(clientSets clients.ClientSets, resourceUID clientTypes.UID, chaosNamespace string) (corev1.EventList, error) {
 	finalEventList := corev1.EventList{}
-	eventsList, err := clients.EventsInformer.Events(chaosNamespace).List(labels.Everything())
+	if clientSets.EventsIndexer != nil {
+		objs, err := clientSets.EventsIndexer.ByIndex(clients.InvolvedObjectUIDIndex, string(resourceUID))
+		if err != nil {
+			return corev1.EventList{}, err
+		}
+		for _, obj := range objs {
+			if event, ok := obj.(*corev1.Event); ok && event.Namespace == chaosNamespace {
+				finalEventList.Items = append(finalEventList.Items, *event)
+			}
+		}
+		return finalEventList, nil
+	}
+
+	eventsList, err := clientSets.EventsInformer.Events(chaosNamespace).List(labels.Everything())
 	if err != nil {
 		return corev1.EventList{}, err
 	}
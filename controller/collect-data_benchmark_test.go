@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientTypes "k8s.io/apimachinery/pkg/types"
+	listerv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/clients"
+)
+
+const (
+	benchmarkResults = 1000
+	benchmarkEvents  = 100000
+)
+
+// newBenchmarkClientSets creates the clientsets with the events cache, containing the events of the given chaosengines
+func newBenchmarkClientSets(tb testing.TB, engines, events int) (clients.ClientSets, []clientTypes.UID) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		clients.InvolvedObjectUIDIndex: clients.InvolvedObjectUIDIndexFunc,
+	})
+	var uids []clientTypes.UID
+	for i := 0; i < engines; i++ {
+		uids = append(uids, clientTypes.UID(fmt.Sprintf("engine-uid-%d", i)))
+	}
+	for i := 0; i < events; i++ {
+		require.NoError(tb, indexer.Add(&corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("event-%d", i),
+				Namespace: "litmus",
+			},
+			InvolvedObject: corev1.ObjectReference{
+				Kind: "ChaosEngine",
+				UID:  uids[i%engines],
+			},
+		}))
+	}
+	return clients.ClientSets{
+		EventsInformer: listerv1.NewEventLister(indexer),
+		EventsIndexer:  indexer,
+	}, uids
+}
+
+func TestGetEventsForSpecificInvolvedResource(t *testing.T) {
+	clientSets, uids := newBenchmarkClientSets(t, 10, 100)
+	scanClientSets := clients.ClientSets{EventsInformer: clientSets.EventsInformer}
+
+	for _, uid := range uids {
+		indexed, err := getEventsForSpecificInvolvedResource(clientSets, uid, "litmus")
+		require.NoError(t, err)
+		require.Len(t, indexed.Items, 10)
+
+		scanned, err := getEventsForSpecificInvolvedResource(scanClientSets, uid, "litmus")
+		require.NoError(t, err)
+		require.ElementsMatch(t, scanned.Items, indexed.Items)
+
+		// the events of the other namespaces are not returned
+		indexed, err = getEventsForSpecificInvolvedResource(clientSets, uid, "default")
+		require.NoError(t, err)
+		require.Empty(t, indexed.Items)
+	}
+}
+
+func BenchmarkGetEventsForSpecificInvolvedResource(b *testing.B) {
+	clientSets, uids := newBenchmarkClientSets(b, benchmarkResults, benchmarkEvents)
+
+	benchmarks := []struct {
+		name       string
+		clientSets clients.ClientSets
+	}{
+		{
+			name:       "scan",
+			clientSets: clients.ClientSets{EventsInformer: clientSets.EventsInformer},
+		},
+		{
+			name:       "index",
+			clientSets: clientSets,
+		},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				// one lookup per chaosresult, as done by a single scrape
+				for _, uid := range uids {
+					if _, err := getEventsForSpecificInvolvedResource(bm.clientSets, uid, "litmus"); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
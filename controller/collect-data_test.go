@@ -3,11 +3,13 @@ package controller_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/litmuschaos/chaos-exporter/controller"
 	"github.com/litmuschaos/chaos-exporter/pkg/clients"
 	"github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
 	litmusFakeClientSet "github.com/litmuschaos/chaos-operator/pkg/client/clientset/versioned/fake"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -17,7 +19,7 @@ import (
 	"k8s.io/client-go/util/workqueue"
 )
 
-func TestGetResultList(t *testing.T) {
+func TestGetResult(t *testing.T) {
 	FakeChaosNameSpace := "Fake Namespace"
 	FakeEngineName := "Fake Engine"
 
@@ -25,7 +27,7 @@ func TestGetResultList(t *testing.T) {
 		name        string
 		execFunc    func(client clients.ClientSets, chaosResult *v1alpha1.ChaosResult)
 		chaosResult *v1alpha1.ChaosResult
-		monitoring  *controller.MonitoringEnabled
+		isFound     bool
 		isErr       bool
 	}{
 		{
@@ -47,20 +49,20 @@ func TestGetResultList(t *testing.T) {
 					EngineName:     FakeEngineName,
 				},
 			},
-
-			isErr: false,
-			monitoring: &controller.MonitoringEnabled{
-				IsChaosResultsAvailable: true,
-			},
+			isFound: true,
+			isErr:   false,
 		},
 		{
-			name:        "success:empty chaosResult",
-			chaosResult: &v1alpha1.ChaosResult{},
-			execFunc:    func(client clients.ClientSets, chaosResult *v1alpha1.ChaosResult) {},
-			isErr:       false,
-			monitoring: &controller.MonitoringEnabled{
-				IsChaosResultsAvailable: true,
+			name: "success:chaos result not found",
+			chaosResult: &v1alpha1.ChaosResult{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      FakeEngineName,
+					Namespace: FakeChaosNameSpace,
+				},
 			},
+			execFunc: func(client clients.ClientSets, chaosResult *v1alpha1.ChaosResult) {},
+			isFound:  false,
+			isErr:    false,
 		},
 	}
 
@@ -68,13 +70,20 @@ func TestGetResultList(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			client := CreateFakeClient(t)
 			tt.execFunc(client, tt.chaosResult)
-			resultDetails := &controller.ResultDetails{}
-			_, err := resultDetails.GetResultList(client, FakeChaosNameSpace, tt.monitoring)
+			resultDetails := controller.NewResultDetails(controller.NewStateStore())
+			var chaosResult *v1alpha1.ChaosResult
+			var err error
+			// wait for the created chaosresult to be observed by the informer
+			require.Eventually(t, func() bool {
+				chaosResult, err = resultDetails.GetResult(client, tt.chaosResult.Namespace, tt.chaosResult.Name)
+				return err != nil || (chaosResult != nil) == tt.isFound
+			}, 5*time.Second, 10*time.Millisecond)
 			if tt.isErr {
 				require.Error(t, err)
 				return
 			}
 			require.NoError(t, err)
+			require.Equal(t, tt.isFound, chaosResult != nil)
 		})
 	}
 }
@@ -91,7 +100,11 @@ func TestGetExperimentMetricsFromResult(t *testing.T) {
 		chaosresult     *v1alpha1.ChaosResult
 		expectedVerdict bool
 		isErr           bool
+		errReason       string
 		verdict         bool
+		faultName       string
+		emptyFaultName  bool
+		targets         []controller.TargetDetails
 		execFunc        func(client clients.ClientSets, engine *v1alpha1.ChaosEngine, result *v1alpha1.ChaosResult)
 	}{
 		"success": {
@@ -156,6 +169,222 @@ func TestGetExperimentMetricsFromResult(t *testing.T) {
 			isErr:   false,
 			verdict: true,
 		},
+		"success: multiple experiments": {
+			chaosengine: &v1alpha1.ChaosEngine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      FakeEngineName,
+					Namespace: FakeNamespace,
+				},
+				Spec: v1alpha1.ChaosEngineSpec{
+					ChaosServiceAccount: fakeServiceAcc,
+					Experiments: []v1alpha1.ExperimentList{
+						{
+							Name: "pod-delete",
+						},
+						{
+							Name: "container-kill",
+						},
+					},
+				},
+				Status: v1alpha1.ChaosEngineStatus{
+					EngineStatus: v1alpha1.EngineStatusInitialized,
+					Experiments: []v1alpha1.ExperimentStatuses{
+						{
+							Name:   "pod-delete",
+							Status: v1alpha1.ExperimentStatusCompleted,
+						},
+						{
+							Name:   "container-kill",
+							Status: v1alpha1.ExperimentStatusRunning,
+						},
+					},
+				},
+			},
+			chaosresult: &v1alpha1.ChaosResult{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      FakeEngineName + "-" + "container-kill",
+					Namespace: FakeNamespace,
+					UID:       "Fake-UID-Multi",
+				},
+				Spec: v1alpha1.ChaosResultSpec{
+					EngineName:     FakeEngineName,
+					ExperimentName: "container-kill",
+				},
+				Status: v1alpha1.ChaosResultStatus{
+					ExperimentStatus: v1alpha1.TestStatus{
+						Phase:   "Running",
+						Verdict: "Awaited",
+					},
+					History: &v1alpha1.HistoryDetails{
+						Targets: []v1alpha1.TargetDetails{
+							{Name: "nginx-7d8f9c-abcde", Kind: "pod", ChaosStatus: "injected"},
+						},
+					},
+				},
+			},
+			execFunc: func(client clients.ClientSets, engine *v1alpha1.ChaosEngine, result *v1alpha1.ChaosResult) {
+				_, err := client.LitmusClient.LitmuschaosV1alpha1().ChaosEngines(engine.Namespace).Create(context.Background(), engine, metav1.CreateOptions{})
+				if err != nil {
+					t.Fatalf("engine not created for test, err: %v", err)
+				}
+				waitForEngineSync(t, client, engine)
+			},
+			isErr:     false,
+			verdict:   false,
+			faultName: "container-kill",
+			targets: []controller.TargetDetails{
+				{Name: "nginx-7d8f9c-abcde", Kind: "pod", ChaosStatus: "injected"},
+			},
+		},
+		"success: chaosresult not matching any experiment": {
+			chaosengine: &v1alpha1.ChaosEngine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      FakeEngineName,
+					Namespace: FakeNamespace,
+				},
+				Spec: v1alpha1.ChaosEngineSpec{
+					ChaosServiceAccount: fakeServiceAcc,
+					Experiments: []v1alpha1.ExperimentList{
+						{
+							Name: "pod-delete",
+						},
+						{
+							Name: "container-kill",
+						},
+					},
+				},
+				Status: v1alpha1.ChaosEngineStatus{
+					EngineStatus: v1alpha1.EngineStatusInitialized,
+					Experiments: []v1alpha1.ExperimentStatuses{
+						{
+							Name:   "pod-delete",
+							Status: v1alpha1.ExperimentStatusCompleted,
+						},
+						{
+							Name:   "container-kill",
+							Status: v1alpha1.ExperimentStatusRunning,
+						},
+					},
+				},
+			},
+			chaosresult: &v1alpha1.ChaosResult{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      FakeEngineName + "-" + "Fake-Exp-Name",
+					Namespace: FakeNamespace,
+					UID:       "Fake-UID-Unmatched",
+				},
+				Spec: v1alpha1.ChaosResultSpec{
+					EngineName: FakeEngineName,
+				},
+				Status: v1alpha1.ChaosResultStatus{
+					ExperimentStatus: v1alpha1.TestStatus{
+						Phase:   "Running",
+						Verdict: "Awaited",
+					},
+					History: &v1alpha1.HistoryDetails{},
+				},
+			},
+			execFunc: func(client clients.ClientSets, engine *v1alpha1.ChaosEngine, result *v1alpha1.ChaosResult) {
+				_, err := client.LitmusClient.LitmuschaosV1alpha1().ChaosEngines(engine.Namespace).Create(context.Background(), engine, metav1.CreateOptions{})
+				if err != nil {
+					t.Fatalf("engine not created for test, err: %v", err)
+				}
+				waitForEngineSync(t, client, engine)
+			},
+			isErr:          false,
+			verdict:        false,
+			emptyFaultName: true,
+		},
+		"success: chaosresult without history": {
+			chaosengine: &v1alpha1.ChaosEngine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      FakeEngineName,
+					Namespace: FakeNamespace,
+				},
+				Spec: v1alpha1.ChaosEngineSpec{
+					ChaosServiceAccount: fakeServiceAcc,
+					Experiments: []v1alpha1.ExperimentList{
+						{
+							Name: "pod-delete",
+						},
+					},
+				},
+			},
+			chaosresult: &v1alpha1.ChaosResult{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      FakeEngineName + "-" + "pod-delete",
+					Namespace: FakeNamespace,
+					UID:       "Fake-UID-Without-History",
+				},
+				Spec: v1alpha1.ChaosResultSpec{
+					EngineName:     FakeEngineName,
+					ExperimentName: "pod-delete",
+				},
+				Status: v1alpha1.ChaosResultStatus{
+					ExperimentStatus: v1alpha1.TestStatus{
+						Phase:   "Running",
+						Verdict: "Awaited",
+					},
+				},
+			},
+			execFunc: func(client clients.ClientSets, engine *v1alpha1.ChaosEngine, result *v1alpha1.ChaosResult) {
+				_, err := client.LitmusClient.LitmuschaosV1alpha1().ChaosEngines(engine.Namespace).Create(context.Background(), engine, metav1.CreateOptions{})
+				if err != nil {
+					t.Fatalf("engine not created for test, err: %v", err)
+				}
+				waitForEngineSync(t, client, engine)
+			},
+			isErr:     false,
+			verdict:   false,
+			faultName: "pod-delete",
+		},
+		"failure: Chaos Engine without experiments": {
+			chaosengine: &v1alpha1.ChaosEngine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      FakeEngineName,
+					Namespace: FakeNamespace,
+				},
+			},
+			chaosresult: &v1alpha1.ChaosResult{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      FakeEngineName + "-" + "Fake-Exp-Name",
+					Namespace: FakeNamespace,
+				},
+				Spec: v1alpha1.ChaosResultSpec{
+					EngineName: FakeEngineName,
+				},
+			},
+			execFunc: func(client clients.ClientSets, engine *v1alpha1.ChaosEngine, result *v1alpha1.ChaosResult) {
+				_, err := client.LitmusClient.LitmuschaosV1alpha1().ChaosEngines(engine.Namespace).Create(context.Background(), engine, metav1.CreateOptions{})
+				if err != nil {
+					t.Fatalf("engine not created for test, err: %v", err)
+				}
+				waitForEngineSync(t, client, engine)
+			},
+			isErr:     true,
+			errReason: controller.ResultErrorEngineWithoutExperiments,
+			verdict:   false,
+		},
+		"failure: invalid probe success percentage": {
+			chaosresult: &v1alpha1.ChaosResult{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      FakeEngineName + "-" + "Fake-Exp-Name",
+					Namespace: FakeNamespace,
+				},
+				Spec: v1alpha1.ChaosResultSpec{
+					EngineName: FakeEngineName,
+				},
+				Status: v1alpha1.ChaosResultStatus{
+					ExperimentStatus: v1alpha1.TestStatus{
+						ProbeSuccessPercentage: "Fake Percentage",
+					},
+				},
+			},
+			execFunc:  func(client clients.ClientSets, engine *v1alpha1.ChaosEngine, result *v1alpha1.ChaosResult) {},
+			isErr:     true,
+			errReason: controller.ResultErrorParse,
+			verdict:   false,
+		},
 		"failure: No Chaos Engine": {
 			chaosresult: &v1alpha1.ChaosResult{},
 			isErr:       false,
@@ -168,19 +397,97 @@ func TestGetExperimentMetricsFromResult(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 			client := CreateFakeClient(t)
-			resultDetails := controller.ResultDetails{}
+			resultDetails := controller.NewResultDetails(controller.NewStateStore())
 			tt.execFunc(client, tt.chaosengine, tt.chaosresult)
 			verdict, err := resultDetails.GetExperimentMetricsFromResult(tt.chaosresult, client)
 			assert.Equal(t, tt.verdict, verdict)
 			if tt.isErr {
 				require.Error(t, err)
+				var resultErr *controller.ResultError
+				require.True(t, errors.As(err, &resultErr))
+				require.Equal(t, tt.errReason, resultErr.Reason)
 				return
 			}
 			require.NoError(t, err)
+			if tt.faultName != "" || tt.emptyFaultName {
+				assert.Equal(t, tt.faultName, resultDetails.GetResultDetails().FaultName)
+			}
+			if tt.targets != nil {
+				assert.Equal(t, tt.targets, resultDetails.GetResultDetails().Targets)
+			}
 		})
 	}
 }
 
+func TestGetProbeDetails(t *testing.T) {
+	chaosResult := &v1alpha1.ChaosResult{
+		Status: v1alpha1.ChaosResultStatus{
+			ProbeStatuses: []v1alpha1.ProbeStatuses{
+				{Name: "check-url", Type: "httpProbe", Mode: "Continuous", Status: v1alpha1.ProbeStatus{Verdict: v1alpha1.ProbeVerdictAwaited}},
+				{Name: "check-url", Type: "httpProbe", Mode: "Edge", Status: v1alpha1.ProbeStatus{Verdict: v1alpha1.ProbeVerdictPassed}},
+				// the repeated probes are merged, keeping the last status
+				{Name: "check-url", Type: "httpProbe", Mode: "Continuous", Status: v1alpha1.ProbeStatus{Verdict: v1alpha1.ProbeVerdictFailed}},
+			},
+		},
+	}
+
+	require.Equal(t, []controller.ProbeDetails{
+		{Name: "check-url", Type: "httpProbe", Mode: "Continuous", Verdict: "Failed"},
+		{Name: "check-url", Type: "httpProbe", Mode: "Edge", Verdict: "Passed"},
+	}, controller.GetProbeDetails(chaosResult))
+	require.Empty(t, controller.GetProbeDetails(&v1alpha1.ChaosResult{}))
+}
+
+func TestGetTargetDetails(t *testing.T) {
+	chaosResult := &v1alpha1.ChaosResult{
+		Status: v1alpha1.ChaosResultStatus{
+			History: &v1alpha1.HistoryDetails{
+				Targets: []v1alpha1.TargetDetails{
+					{Name: "nginx-1", Kind: "pod", ChaosStatus: "injected"},
+					{Name: "nginx-2", Kind: "pod", ChaosStatus: "injected"},
+					{Name: "nginx-3", Kind: "pod", ChaosStatus: "targeted"},
+				},
+			},
+		},
+	}
+
+	require.Len(t, controller.GetTargetDetails(chaosResult), 3)
+	// the targets are capped by the TARGET_METRICS_LIMIT
+	t.Setenv("TARGET_METRICS_LIMIT", "2")
+	require.Equal(t, []controller.TargetDetails{
+		{Name: "nginx-1", Kind: "pod", ChaosStatus: "injected"},
+		{Name: "nginx-2", Kind: "pod", ChaosStatus: "injected"},
+	}, controller.GetTargetDetails(chaosResult))
+	// the chaosresult without history doesn't contain any target
+	require.Empty(t, controller.GetTargetDetails(&v1alpha1.ChaosResult{}))
+
+	// the repeated targets are merged, keeping the last chaos status
+	chaosResult.Status.History.Targets = []v1alpha1.TargetDetails{
+		{Name: "node-1", Kind: "node", ChaosStatus: "targeted"},
+		{Name: "node-1", Kind: "pod", ChaosStatus: "targeted"},
+		{Name: "node-1", Kind: "node", ChaosStatus: "injected"},
+	}
+	require.Equal(t, []controller.TargetDetails{
+		{Name: "node-1", Kind: "node", ChaosStatus: "injected"},
+		{Name: "node-1", Kind: "pod", ChaosStatus: "targeted"},
+	}, controller.GetTargetDetails(chaosResult))
+}
+
+// waitForEngineSync waits until the chaosengine is available inside the informer cache
+func waitForEngineSync(t *testing.T, client clients.ClientSets, engine *v1alpha1.ChaosEngine) {
+	require.Eventually(t, func() bool {
+		_, err := client.EngineInformer.ChaosEngines(engine.Namespace).Get(engine.Name)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestGetExperimentMetricsFromResultWithoutStateStore(t *testing.T) {
+	// the state store is not created implicitly, as it must be shared with the MetricesCollecter
+	resultDetails := controller.ResultDetails{}
+	_, err := resultDetails.GetExperimentMetricsFromResult(&v1alpha1.ChaosResult{}, clients.ClientSets{})
+	require.Error(t, err)
+}
+
 func CreateFakeClient(t *testing.T) clients.ClientSets {
 	cs := clients.ClientSets{}
 	cs.KubeClient = fake.NewSimpleClientset([]runtime.Object{}...)
@@ -17,67 +17,133 @@ limitations under the License.
 package controller
 
 import (
+	"os"
+	"sync/atomic"
+	"time"
+
 	"github.com/litmuschaos/chaos-exporter/pkg/clients"
+	"github.com/litmuschaos/chaos-exporter/pkg/health"
 	"github.com/litmuschaos/chaos-exporter/pkg/log"
-	litmuschaosv1alpha1 "github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
 	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/util/workqueue"
 )
 
+// ExporterOptions contains the optional dependencies of the exporter
+type ExporterOptions struct {
+	// Health records every successful metrics collection
+	Health *health.Checker
+	// StateBackend persists the verdict details across the exporter restarts
+	StateBackend StateBackend
+	// Sinks publish the chaos metrics to the external backends
+	Sinks []Sink
+	// Notifiers are notified about the chaosresult verdict transitions
+	Notifiers []VerdictNotifier
+}
+
 // Exporter continuously collects the chaos metrics for a given chaosengine
-func Exporter(clientSet clients.ClientSets, wq workqueue.RateLimitingInterface) {
+func Exporter(clientSet clients.ClientSets, wq workqueue.RateLimitingInterface, options ExporterOptions) {
 	log.Info("Started creating Metrics")
-	// Register the fixed (count) chaos metrics
-	log.Info("Registering Fixed Metrics")
+	// Register the chaos metrics, which are rendered from the snapshot of the processed chaosresults
+	log.Info("Registering Chaos Metrics")
 
+	watchNamespace := os.Getenv("WATCH_NAMESPACE")
+	state := NewStateStore()
 	r := MetricesCollecter{
-		ResultCollector: &ResultDetails{},
+		ResultCollector: NewResultDetails(state),
+		Metrics:         NewChaosMetricsCollector(watchNamespace),
+		State:           state,
+		Sinks:           options.Sinks,
+		Notifiers:       options.Notifiers,
+		Aggregates:      NewResultAggregates(),
 	}
-	//gaugeMetrics := GaugeMetrics{}
-	overallChaosResults := []*litmuschaosv1alpha1.ChaosResult{}
+	prometheus.MustRegister(r.Metrics)
+	r.ExporterMetrics.InitializeExporterMetrics(wq).
+		RegisterExporterMetrics()
+	// Register the chaosengine lifecycle metrics, which are collected on every scrape
+	prometheus.MustRegister(NewEngineCollector(clientSet.EngineInformer))
 
-	r.GaugeMetrics.InitializeGaugeMetrics().
-		RegisterFixedMetrics()
+	// restore the verdict details persisted by the previous exporter instance,
+	// so that the already exported verdicts are not exported again
+	var savedVersion uint64
+	if options.StateBackend != nil {
+		verdicts, err := options.StateBackend.Load()
+		if err != nil {
+			log.Errorf("Unable to load the exporter state, err: %v", err)
+		} else {
+			state.RestoreVerdicts(verdicts)
+			_, savedVersion = state.Verdicts()
+			log.Infof("Restored the exporter state of %d chaosresults", len(verdicts))
+		}
+	}
+	// prune the state of the chaosresults deleted before they are observed, e.g, while the exporter was down
+	if resultList, err := clientSet.ResultInformer.ChaosResults(watchNamespace).List(labels.Everything()); err != nil {
+		log.Errorf("Unable to list the chaosresults, err: %v", err)
+	} else {
+		state.Retain(getResultUIDs(resultList))
+	}
 
-	monitoringEnabled := MonitoringEnabled{
-		IsChaosResultsAvailable: true,
-		IsChaosEnginesAvailable: true,
+	// the exporter is alive while it is waiting for the changes of the chaosresults, even if there are no chaosresults
+	// the failed chaosresults are pending until they are collected successfully, even while they wait for the backoff
+	var processing, failing int32
+	failedKeys := map[string]bool{}
+	if options.Health != nil {
+		options.Health.SetIdle(func() bool {
+			return wq.Len() == 0 && atomic.LoadInt32(&processing) == 0 && atomic.LoadInt32(&failing) == 0
+		})
 	}
 
-	// refresh metrics whenever there's a change in chaosengine or chaosresult
+	// refresh the metrics of the chaosresult whenever there's a change in the chaosresult or its chaosengine
 	// or every informer resync duration, whichever is earlier
-	for _, done := wq.Get(); !done; _, done = wq.Get() {
-		needRequeue, err := r.GetLitmusChaosMetrics(clientSet, &overallChaosResults, &monitoringEnabled)
+	for item, done := wq.Get(); !done; item, done = wq.Get() {
+		key := item.(string)
+		atomic.StoreInt32(&processing, 1)
+		startTime := time.Now()
+		needRequeue, err := r.ReconcileResult(clientSet, key)
+		r.ExporterMetrics.ReconcileDuration.Observe(time.Since(startTime).Seconds())
+		wq.Done(key)
+		atomic.StoreInt32(&processing, 0)
 		if err != nil {
-			log.Errorf("err: %v", err)
+			log.Errorf("Unable to process chaosresult %s, err: %v", key, err)
+			// retry the failed collection with the rate limited backoff
+			r.ExporterMetrics.ReconcileErrors.WithLabelValues(getErrorClass(err)).Inc()
+			r.ExporterMetrics.WorkqueueRetries.Inc()
+			wq.AddRateLimited(key)
+			failedKeys[key] = true
+			atomic.StoreInt32(&failing, int32(len(failedKeys)))
+			continue
+		}
+		wq.Forget(key)
+		delete(failedKeys, key)
+		atomic.StoreInt32(&failing, int32(len(failedKeys)))
+		if options.Health != nil {
+			options.Health.RecordSuccess()
+		}
+		// publish the aggregate metrics and checkpoint the verdict details, if modified since the
+		// last checkpoint, once all the queued up chaosresults are processed
+		if wq.Len() == 0 {
+			r.PublishAggregates()
+			if options.StateBackend != nil {
+				savedVersion = checkpointState(options.StateBackend, state, savedVersion)
+			}
 		}
-		wq.Done(clients.ProcessKey)
 		// Add after
 		if needRequeue != nil {
-			wq.AddAfter(clients.ProcessKey, *needRequeue)
+			wq.AddAfter(key, *needRequeue)
 		}
 	}
 }
 
-// RegisterFixedMetrics register the prometheus metrics
-func (gaugeMetrics *GaugeMetrics) RegisterFixedMetrics() {
-	prometheus.MustRegister(gaugeMetrics.ResultPassedExperiments)
-	prometheus.MustRegister(gaugeMetrics.ResultFailedExperiments)
-	prometheus.MustRegister(gaugeMetrics.ResultAwaitedExperiments)
-	prometheus.MustRegister(gaugeMetrics.ResultProbeSuccessPercentage)
-	prometheus.MustRegister(gaugeMetrics.ResultVerdict)
-	prometheus.MustRegister(gaugeMetrics.ExperimentStartTime)
-	prometheus.MustRegister(gaugeMetrics.ExperimentEndTime)
-	prometheus.MustRegister(gaugeMetrics.ExperimentChaosInjectedTime)
-	prometheus.MustRegister(gaugeMetrics.ExperimentTotalDuration)
-	prometheus.MustRegister(gaugeMetrics.ClusterScopedTotalPassedExperiments)
-	prometheus.MustRegister(gaugeMetrics.ClusterScopedTotalFailedExperiments)
-	prometheus.MustRegister(gaugeMetrics.ClusterScopedTotalAwaitedExperiments)
-	prometheus.MustRegister(gaugeMetrics.ClusterScopedExperimentsRunCount)
-	prometheus.MustRegister(gaugeMetrics.ClusterScopedExperimentsInstalledCount)
-	prometheus.MustRegister(gaugeMetrics.NamespaceScopedTotalPassedExperiments)
-	prometheus.MustRegister(gaugeMetrics.NamespaceScopedTotalFailedExperiments)
-	prometheus.MustRegister(gaugeMetrics.NamespaceScopedTotalAwaitedExperiments)
-	prometheus.MustRegister(gaugeMetrics.NamespaceScopedExperimentsRunCount)
-	prometheus.MustRegister(gaugeMetrics.NamespaceScopedExperimentsInstalledCount)
+// checkpointState saves the verdict details inside the state backend if those are modified
+// since the given version, it returns the version which is saved
+func checkpointState(backend StateBackend, state *StateStore, savedVersion uint64) uint64 {
+	verdicts, version := state.Verdicts()
+	if version == savedVersion {
+		return savedVersion
+	}
+	if err := backend.Save(verdicts); err != nil {
+		log.Errorf("Unable to save the exporter state, err: %v", err)
+		return savedVersion
+	}
+	return version
 }
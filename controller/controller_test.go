@@ -0,0 +1,43 @@
+package controller_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/controller"
+	"github.com/litmuschaos/chaos-exporter/pkg/health"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestExporterLiveness(t *testing.T) {
+	client := CreateFakeClient(t)
+	wq := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	checker := health.NewChecker(health.Config{LivenessThreshold: time.Millisecond})
+	checker.SetInformersSynced(client.InformersSynced)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		controller.Exporter(client, wq, controller.ExporterOptions{Health: checker})
+	}()
+
+	// no chaosresult is ever reconciled, the exporter is still alive after the liveness threshold
+	time.Sleep(10 * time.Millisecond)
+	require.Eventually(t, func() bool {
+		return checker.Alive() == nil
+	}, time.Second, 10*time.Millisecond)
+
+	// the chaosresult which keeps failing is pending while it waits for the backoff, the exporter
+	// isn't alive once the liveness threshold is passed without any successful collection
+	wq.Add("invalid/chaosresult/key")
+	require.Eventually(t, func() bool {
+		return checker.Alive() != nil
+	}, time.Second, 10*time.Millisecond)
+	require.Never(t, func() bool {
+		return checker.Alive() == nil
+	}, 200*time.Millisecond, 5*time.Millisecond)
+
+	wq.ShutDown()
+	<-done
+}
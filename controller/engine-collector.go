@@ -0,0 +1,204 @@
+/*
+Copyright 2019 LitmusChaos Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	litmuschaosv1alpha1 "github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
+	listerv1alpha1 "github.com/litmuschaos/chaos-operator/pkg/client/listers/litmuschaos/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/labels"
+	clientTypes "k8s.io/apimachinery/pkg/types"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/log"
+)
+
+// pendingEngineStatus is exported for the chaosengines, which are not yet reconciled by the chaos-operator
+const pendingEngineStatus = "pending"
+
+// enginePhase contains the engine status observed by the collector and the time since when it is observed
+type enginePhase struct {
+	status string
+	since  time.Time
+}
+
+// EngineCollector exports the lifecycle metrics of the chaosengines, independent of the chaosresults
+// The chaosengines are listed from the informer cache on every scrape
+type EngineCollector struct {
+	lister listerv1alpha1.ChaosEngineLister
+	// now returns the current time, it is overridden inside the tests
+	now func() time.Time
+
+	mu sync.Mutex
+	// phases contains the last observed phase of the chaosengines, keyed by the chaosengine uid
+	phases map[clientTypes.UID]enginePhase
+
+	engineStatus     *prometheus.Desc
+	engineState      *prometheus.Desc
+	experimentStatus *prometheus.Desc
+	phaseAge         *prometheus.Desc
+	activeEngines    *prometheus.Desc
+}
+
+// NewEngineCollector creates the collector, which lists the chaosengines from the given lister
+func NewEngineCollector(lister listerv1alpha1.ChaosEngineLister) *EngineCollector {
+	engineLabels := []string{"chaosengine_namespace", "chaosengine_name", "chaosengine_context", "workflow_name"}
+	return &EngineCollector{
+		lister: lister,
+		now:    time.Now,
+		phases: map[clientTypes.UID]enginePhase{},
+		engineStatus: prometheus.NewDesc("litmuschaos_engine_status",
+			"Status of the chaosengines, the engine_status label contains the status",
+			append(engineLabels, "engine_status"), nil),
+		engineState: prometheus.NewDesc("litmuschaos_engine_state",
+			"Desired state of the chaosengines, the engine_state label contains the state",
+			append(engineLabels, "engine_state"), nil),
+		experimentStatus: prometheus.NewDesc("litmuschaos_engine_experiment_status",
+			"Status of the individual experiments of the chaosengines, the experiment_status label contains the status",
+			append(engineLabels, "experiment_name", "experiment_status"), nil),
+		phaseAge: prometheus.NewDesc("litmuschaos_engine_phase_age_seconds",
+			"Time since the chaosengines are in the current status",
+			append(engineLabels, "engine_status"), nil),
+		activeEngines: prometheus.NewDesc("litmuschaos_active_engines",
+			"Number of the chaosengines, which are neither completed nor stopped",
+			[]string{"chaosengine_namespace"}, nil),
+	}
+}
+
+// Describe implements the prometheus.Collector interface
+func (c *EngineCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.engineStatus
+	ch <- c.engineState
+	ch <- c.experimentStatus
+	ch <- c.phaseAge
+	ch <- c.activeEngines
+}
+
+// Collect implements the prometheus.Collector interface
+func (c *EngineCollector) Collect(ch chan<- prometheus.Metric) {
+	engines, err := c.lister.List(labels.Everything())
+	if err != nil {
+		log.Errorf("Unable to list the chaosengines, err: %v", err)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	activeEngines := map[string]float64{}
+	seen := make(map[clientTypes.UID]bool, len(engines))
+	for _, engine := range engines {
+		seen[engine.UID] = true
+		status := getEngineStatus(engine)
+		phase := c.observePhase(engine, status, now)
+		engineLabels := []string{engine.Namespace, engine.Name, engine.Labels[EngineContext], engine.Labels[WorkFlowName]}
+
+		sendMetric(ch, c.engineStatus, prometheus.GaugeValue, 1, append(engineLabels, status)...)
+		sendMetric(ch, c.engineState, prometheus.GaugeValue, 1, append(engineLabels, string(engine.Spec.EngineState))...)
+		sendMetric(ch, c.phaseAge, prometheus.GaugeValue, now.Sub(phase.since).Seconds(), append(engineLabels, status)...)
+		for _, experiment := range getExperimentStatuses(engine) {
+			sendMetric(ch, c.experimentStatus, prometheus.GaugeValue, 1, append(engineLabels, experiment.Name, string(experiment.Status))...)
+		}
+
+		if _, ok := activeEngines[engine.Namespace]; !ok {
+			activeEngines[engine.Namespace] = 0
+		}
+		if isActiveEngine(status) {
+			activeEngines[engine.Namespace]++
+		}
+	}
+	for namespace, count := range activeEngines {
+		sendMetric(ch, c.activeEngines, prometheus.GaugeValue, count, namespace)
+	}
+
+	// prune the phases of the deleted chaosengines
+	for uid := range c.phases {
+		if !seen[uid] {
+			delete(c.phases, uid)
+		}
+	}
+}
+
+// observePhase returns the phase of the chaosengine, the phase start time is updated whenever the status changes
+// the phase of a chaosengine observed for the first time is derived from the chaosengine itself
+func (c *EngineCollector) observePhase(engine *litmuschaosv1alpha1.ChaosEngine, status string, now time.Time) enginePhase {
+	phase, ok := c.phases[engine.UID]
+	switch {
+	case !ok:
+		phase = enginePhase{status: status, since: getPhaseStartTime(engine, status, now)}
+	case phase.status != status:
+		phase = enginePhase{status: status, since: now}
+	default:
+		return phase
+	}
+	c.phases[engine.UID] = phase
+	return phase
+}
+
+// getEngineStatus returns the status of the chaosengine, pending if it is not yet reconciled
+func getEngineStatus(engine *litmuschaosv1alpha1.ChaosEngine) string {
+	if engine.Status.EngineStatus == "" {
+		return pendingEngineStatus
+	}
+	return string(engine.Status.EngineStatus)
+}
+
+// getExperimentStatuses returns the experiment statuses of the chaosengine, the repeated experiments are
+// merged by their name, keeping the last status, as the duplicate series fail the complete scrape
+func getExperimentStatuses(engine *litmuschaosv1alpha1.ChaosEngine) []litmuschaosv1alpha1.ExperimentStatuses {
+	var experiments []litmuschaosv1alpha1.ExperimentStatuses
+	indexes := map[string]int{}
+	for _, experiment := range engine.Status.Experiments {
+		if i, ok := indexes[experiment.Name]; ok {
+			experiments[i] = experiment
+			continue
+		}
+		indexes[experiment.Name] = len(experiments)
+		experiments = append(experiments, experiment)
+	}
+	return experiments
+}
+
+// getPhaseStartTime derive the start time of the current phase from the chaosengine
+// the pending and initialized chaosengines are in the phase since their creation, while the
+// completed and stopped chaosengines are in the phase since the last update of their experiments
+func getPhaseStartTime(engine *litmuschaosv1alpha1.ChaosEngine, status string, now time.Time) time.Time {
+	switch status {
+	case pendingEngineStatus, string(litmuschaosv1alpha1.EngineStatusInitialized):
+		if !engine.CreationTimestamp.IsZero() {
+			return engine.CreationTimestamp.Time
+		}
+	default:
+		var lastUpdateTime time.Time
+		for _, experiment := range engine.Status.Experiments {
+			if experiment.LastUpdateTime.After(lastUpdateTime) {
+				lastUpdateTime = experiment.LastUpdateTime.Time
+			}
+		}
+		if !lastUpdateTime.IsZero() {
+			return lastUpdateTime
+		}
+	}
+	return now
+}
+
+// isActiveEngine checks whether the chaosengine having the given status is still running the chaos
+func isActiveEngine(status string) bool {
+	return status != string(litmuschaosv1alpha1.EngineStatusCompleted) && status != string(litmuschaosv1alpha1.EngineStatusStopped)
+}
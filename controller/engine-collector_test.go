@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
+	listerv1alpha1 "github.com/litmuschaos/chaos-operator/pkg/client/listers/litmuschaos/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestEngineCollector(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	collector := NewEngineCollector(listerv1alpha1.NewChaosEngineLister(indexer))
+	collector.now = func() time.Time { return now }
+
+	engines := []*v1alpha1.ChaosEngine{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "engine-pending",
+				Namespace:         "litmus",
+				UID:               "UID-1",
+				CreationTimestamp: metav1.NewTime(now.Add(-time.Minute)),
+			},
+			Spec: v1alpha1.ChaosEngineSpec{EngineState: v1alpha1.EngineStateActive},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "engine-running",
+				Namespace:         "litmus",
+				UID:               "UID-2",
+				CreationTimestamp: metav1.NewTime(now.Add(-10 * time.Minute)),
+				Labels:            map[string]string{EngineContext: "test"},
+			},
+			Spec: v1alpha1.ChaosEngineSpec{EngineState: v1alpha1.EngineStateActive},
+			Status: v1alpha1.ChaosEngineStatus{
+				EngineStatus: v1alpha1.EngineStatusInitialized,
+				Experiments: []v1alpha1.ExperimentStatuses{
+					{Name: "pod-delete", Status: v1alpha1.ExperimentStatusRunning},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "engine-stopped",
+				Namespace: "team-a",
+				UID:       "UID-3",
+			},
+			Spec: v1alpha1.ChaosEngineSpec{EngineState: v1alpha1.EngineStateStop},
+			Status: v1alpha1.ChaosEngineStatus{
+				EngineStatus: v1alpha1.EngineStatusStopped,
+				Experiments: []v1alpha1.ExperimentStatuses{
+					{Name: "pod-delete", Status: v1alpha1.ExperimentStatusAborted, LastUpdateTime: metav1.NewTime(now.Add(-30 * time.Second))},
+				},
+			},
+		},
+	}
+	for _, engine := range engines {
+		require.NoError(t, indexer.Add(engine))
+	}
+
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP litmuschaos_active_engines Number of the chaosengines, which are neither completed nor stopped
+# TYPE litmuschaos_active_engines gauge
+litmuschaos_active_engines{chaosengine_namespace="litmus"} 2
+litmuschaos_active_engines{chaosengine_namespace="team-a"} 0
+# HELP litmuschaos_engine_experiment_status Status of the individual experiments of the chaosengines, the experiment_status label contains the status
+# TYPE litmuschaos_engine_experiment_status gauge
+litmuschaos_engine_experiment_status{chaosengine_context="",chaosengine_name="engine-stopped",chaosengine_namespace="team-a",experiment_name="pod-delete",experiment_status="Forcefully Aborted",workflow_name=""} 1
+litmuschaos_engine_experiment_status{chaosengine_context="test",chaosengine_name="engine-running",chaosengine_namespace="litmus",experiment_name="pod-delete",experiment_status="Running",workflow_name=""} 1
+# HELP litmuschaos_engine_phase_age_seconds Time since the chaosengines are in the current status
+# TYPE litmuschaos_engine_phase_age_seconds gauge
+litmuschaos_engine_phase_age_seconds{chaosengine_context="",chaosengine_name="engine-pending",chaosengine_namespace="litmus",engine_status="pending",workflow_name=""} 60
+litmuschaos_engine_phase_age_seconds{chaosengine_context="",chaosengine_name="engine-stopped",chaosengine_namespace="team-a",engine_status="stopped",workflow_name=""} 30
+litmuschaos_engine_phase_age_seconds{chaosengine_context="test",chaosengine_name="engine-running",chaosengine_namespace="litmus",engine_status="initialized",workflow_name=""} 600
+# HELP litmuschaos_engine_state Desired state of the chaosengines, the engine_state label contains the state
+# TYPE litmuschaos_engine_state gauge
+litmuschaos_engine_state{chaosengine_context="",chaosengine_name="engine-pending",chaosengine_namespace="litmus",engine_state="active",workflow_name=""} 1
+litmuschaos_engine_state{chaosengine_context="",chaosengine_name="engine-stopped",chaosengine_namespace="team-a",engine_state="stop",workflow_name=""} 1
+litmuschaos_engine_state{chaosengine_context="test",chaosengine_name="engine-running",chaosengine_namespace="litmus",engine_state="active",workflow_name=""} 1
+# HELP litmuschaos_engine_status Status of the chaosengines, the engine_status label contains the status
+# TYPE litmuschaos_engine_status gauge
+litmuschaos_engine_status{chaosengine_context="",chaosengine_name="engine-pending",chaosengine_namespace="litmus",engine_status="pending",workflow_name=""} 1
+litmuschaos_engine_status{chaosengine_context="",chaosengine_name="engine-stopped",chaosengine_namespace="team-a",engine_status="stopped",workflow_name=""} 1
+litmuschaos_engine_status{chaosengine_context="test",chaosengine_name="engine-running",chaosengine_namespace="litmus",engine_status="initialized",workflow_name=""} 1
+`)))
+
+	// the phase age restarts once the status changes
+	completed := engines[1].DeepCopy()
+	completed.Status.EngineStatus = v1alpha1.EngineStatusCompleted
+	require.NoError(t, indexer.Update(completed))
+	now = now.Add(5 * time.Second)
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP litmuschaos_engine_phase_age_seconds Time since the chaosengines are in the current status
+# TYPE litmuschaos_engine_phase_age_seconds gauge
+litmuschaos_engine_phase_age_seconds{chaosengine_context="",chaosengine_name="engine-pending",chaosengine_namespace="litmus",engine_status="pending",workflow_name=""} 65
+litmuschaos_engine_phase_age_seconds{chaosengine_context="",chaosengine_name="engine-stopped",chaosengine_namespace="team-a",engine_status="stopped",workflow_name=""} 35
+litmuschaos_engine_phase_age_seconds{chaosengine_context="test",chaosengine_name="engine-running",chaosengine_namespace="litmus",engine_status="completed",workflow_name=""} 0
+`), "litmuschaos_engine_phase_age_seconds"))
+
+	// the phases of the deleted chaosengines are pruned
+	for _, engine := range engines {
+		require.NoError(t, indexer.Delete(engine))
+	}
+	require.Zero(t, testutil.CollectAndCount(collector))
+	require.Empty(t, collector.phases)
+}
+
+func TestEngineCollectorInvalidLabels(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	collector := NewEngineCollector(listerv1alpha1.NewChaosEngineLister(indexer))
+	require.NoError(t, indexer.Add(&v1alpha1.ChaosEngine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "engine",
+			Namespace: "litmus",
+			UID:       "UID",
+			// the invalid utf-8 label value can't be exported
+			Labels: map[string]string{EngineContext: "\xff"},
+		},
+	}))
+
+	// the metrics with the invalid label values are skipped, instead of failing the scrape
+	require.NotPanics(t, func() {
+		require.Zero(t, testutil.CollectAndCount(collector, "litmuschaos_engine_status"))
+	})
+	require.Equal(t, 1, testutil.CollectAndCount(collector, "litmuschaos_active_engines"))
+}
+
+func TestEngineCollectorRepeatedExperiments(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	collector := NewEngineCollector(listerv1alpha1.NewChaosEngineLister(indexer))
+	require.NoError(t, indexer.Add(&v1alpha1.ChaosEngine{
+		ObjectMeta: metav1.ObjectMeta{Name: "engine", Namespace: "litmus", UID: "UID"},
+		Status: v1alpha1.ChaosEngineStatus{
+			EngineStatus: v1alpha1.EngineStatusInitialized,
+			Experiments: []v1alpha1.ExperimentStatuses{
+				{Name: "pod-delete", Status: v1alpha1.ExperimentStatusRunning},
+				{Name: "pod-delete", Status: v1alpha1.ExperimentStatusCompleted},
+			},
+		},
+	}))
+
+	// the repeated experiments are exported once with the last status, instead of failing the scrape
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(collector))
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP litmuschaos_engine_experiment_status Status of the individual experiments of the chaosengines, the experiment_status label contains the status
+# TYPE litmuschaos_engine_experiment_status gauge
+litmuschaos_engine_experiment_status{chaosengine_context="",chaosengine_name="engine",chaosengine_namespace="litmus",experiment_name="pod-delete",experiment_status="Completed",workflow_name=""} 1
+`), "litmuschaos_engine_experiment_status"))
+}
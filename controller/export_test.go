@@ -0,0 +1,8 @@
+package controller
+
+// the unexported functions used by the external tests
+var (
+	GetTargetDetails   = getTargetDetails
+	GetProbeDetails    = getProbeDetails
+	GetFailureCategory = getFailureCategory
+)
@@ -0,0 +1,132 @@
+/*
+Copyright 2019 LitmusChaos Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/clients"
+)
+
+// ExporterMetrics contains the metrics definition of the exporter itself
+type ExporterMetrics struct {
+	ReconcileDuration prometheus.Histogram
+	ReconcileErrors   *prometheus.CounterVec
+	ResultErrors      *prometheus.CounterVec
+	WorkqueueDepth    prometheus.GaugeFunc
+	WorkqueueRetries  prometheus.Counter
+}
+
+// InitializeExporterMetrics defines schema of the exporter metrics
+func (exporterMetrics *ExporterMetrics) InitializeExporterMetrics(wq workqueue.RateLimitingInterface) *ExporterMetrics {
+	exporterMetrics.ReconcileDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "chaos_exporter",
+		Subsystem: "",
+		Name:      "reconcile_duration_seconds",
+		Help:      "Time taken to collect the chaos metrics of a chaosresult",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	exporterMetrics.ReconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "chaos_exporter",
+		Subsystem: "",
+		Name:      "reconcile_errors_total",
+		Help:      "Total number of failed chaos metrics collections",
+	},
+		[]string{"class"},
+	)
+
+	exporterMetrics.ResultErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "chaos_exporter",
+		Subsystem: "",
+		Name:      "result_errors_total",
+		Help:      "Total number of chaosresults skipped due to the errors in deriving their chaos metrics",
+	},
+		[]string{"namespace", "result", "reason"},
+	)
+
+	exporterMetrics.WorkqueueDepth = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: "chaos_exporter",
+		Subsystem: "workqueue",
+		Name:      "depth",
+		Help:      "Current depth of the workqueue",
+	},
+		func() float64 {
+			return float64(wq.Len())
+		},
+	)
+
+	exporterMetrics.WorkqueueRetries = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "chaos_exporter",
+		Subsystem: "workqueue",
+		Name:      "retries_total",
+		Help:      "Total number of rate limited retries handled by the workqueue",
+	})
+	return exporterMetrics
+}
+
+// RegisterExporterMetrics register the exporter metrics
+func (exporterMetrics *ExporterMetrics) RegisterExporterMetrics() {
+	prometheus.MustRegister(exporterMetrics.ReconcileDuration)
+	prometheus.MustRegister(exporterMetrics.ReconcileErrors)
+	prometheus.MustRegister(exporterMetrics.ResultErrors)
+	prometheus.MustRegister(exporterMetrics.WorkqueueDepth)
+	prometheus.MustRegister(exporterMetrics.WorkqueueRetries)
+	prometheus.MustRegister(clients.InformerEvents)
+}
+
+// getErrorClass classifies the error returned by the chaos metrics collection
+func getErrorClass(err error) string {
+	var (
+		engineErr *EngineWithoutExperimentsError
+		numErr    *strconv.NumError
+		apiErr    k8serrors.APIStatus
+	)
+	switch {
+	case errors.As(err, &engineErr):
+		return "engine_without_experiments"
+	case errors.As(err, &numErr):
+		return "parse"
+	case errors.As(err, &apiErr):
+		return "kubernetes_api"
+	default:
+		return "unknown"
+	}
+}
+
+// recordResultError counts the error of the given chaosresult, which is skipped
+func (exporterMetrics *ExporterMetrics) recordResultError(namespace, name, reason string) {
+	if exporterMetrics.ResultErrors == nil {
+		return
+	}
+	exporterMetrics.ResultErrors.WithLabelValues(namespace, name, reason).Inc()
+}
+
+// unsetResultErrors removes the error counts of the deleted chaosresult
+func (exporterMetrics *ExporterMetrics) unsetResultErrors(namespace, name string) {
+	if exporterMetrics.ResultErrors == nil {
+		return
+	}
+	for _, reason := range resultErrorReasons {
+		exporterMetrics.ResultErrors.DeleteLabelValues(namespace, name, reason)
+	}
+}
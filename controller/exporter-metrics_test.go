@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func Test_getErrorClass(t *testing.T) {
+	_, parseErr := strconv.ParseFloat("fake", 64)
+
+	tests := map[string]struct {
+		err           error
+		expectedClass string
+	}{
+		"engine without experiments": {
+			err:           &EngineWithoutExperimentsError{EngineName: "engine"},
+			expectedClass: "engine_without_experiments",
+		},
+		"probe success percentage parse error": {
+			err:           errors.Wrap(parseErr, "unable to parse"),
+			expectedClass: "parse",
+		},
+		"kubernetes api error": {
+			err:           k8serrors.NewNotFound(schema.GroupResource{Resource: "chaosengines"}, "engine"),
+			expectedClass: "kubernetes_api",
+		},
+		"unknown error": {
+			err:           errors.New("fake error"),
+			expectedClass: "unknown",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tt.expectedClass, getErrorClass(tt.err))
+		})
+	}
+}
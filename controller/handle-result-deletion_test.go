@@ -1,79 +1,28 @@
 package controller
 
 import (
-	"errors"
 	"testing"
 
-	"github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
 	"github.com/stretchr/testify/require"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func Test_unsetDeletedChaosResults(t *testing.T) {
-
-	tests := []struct {
-		name           string
-		execFunc       func(details *ChaosResultDetails)
-		isErr          bool
-		resultDetails  *ChaosResultDetails
-		oldChaosResult []*v1alpha1.ChaosResult
-		newChaosResult []*v1alpha1.ChaosResult
-	}{
-		{
-			name: "success: deleted chaosResult",
-			execFunc: func(details *ChaosResultDetails) {
-				details.setResultData()
-			},
-			resultDetails: &ChaosResultDetails{
-				UID: "FAKE-UID-OLD",
-			},
-			oldChaosResult: []*v1alpha1.ChaosResult{
-				{
-					ObjectMeta: metav1.ObjectMeta{
-						UID: "FAKE-UID-OLD",
-					},
-				},
-			},
-			newChaosResult: []*v1alpha1.ChaosResult{
-				{
-					ObjectMeta: metav1.ObjectMeta{
-						UID: "FAKE-UID-NEW",
-					},
-				},
-			},
-			isErr: false,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			tt.execFunc(tt.resultDetails)
-
-			r := MetricesCollecter{}
-			r.GaugeMetrics.InitializeGaugeMetrics()
-			r.GaugeMetrics.unsetDeletedChaosResults(tt.oldChaosResult, tt.newChaosResult)
-			if len(resultStore) != 0 && tt.isErr {
-				require.Error(t, errors.New("not able to remove result from resultStore"))
-			}
-		})
-	}
-
-}
-
-func Test_unsetOutdatedChaosResults(t *testing.T) {
+func Test_updateVerdictState(t *testing.T) {
+	t.Parallel()
 
 	tests := []struct {
 		name     string
-		execFunc func(details ChaosResultDetails)
+		execFunc func(details ChaosResultDetails, state *StateStore)
 		isErr    bool
 
 		oldResultDetails ChaosResultDetails
 		newResultDetails ChaosResultDetails
+		expectedChanges  int
 	}{
 		{
 			name: "success: verdict changed",
-			execFunc: func(details ChaosResultDetails) {
+			execFunc: func(details ChaosResultDetails, state *StateStore) {
 				r := &ResultData{}
-				matchVerdict[string(details.UID)] = r.setVerdict(details.Verdict)
+				state.SetVerdict(string(details.UID), *r.setVerdict(details.Verdict))
 			},
 			oldResultDetails: ChaosResultDetails{
 				UID:     "UID",
@@ -83,15 +32,41 @@ func Test_unsetOutdatedChaosResults(t *testing.T) {
 				UID:     "UID",
 				Verdict: "Pass",
 			},
+			expectedChanges: 1,
+		},
+		{
+			name: "success: verdict not changed",
+			execFunc: func(details ChaosResultDetails, state *StateStore) {
+				r := &ResultData{}
+				state.SetVerdict(string(details.UID), *r.setVerdict(details.Verdict))
+			},
+			oldResultDetails: ChaosResultDetails{
+				UID:     "UID",
+				Verdict: "Awaited",
+			},
+			newResultDetails: ChaosResultDetails{
+				UID:     "UID",
+				Verdict: "Awaited",
+			},
+			expectedChanges: 0,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tt.execFunc(tt.oldResultDetails)
+			notifier := &fakeNotifier{}
+			r := MetricesCollecter{State: NewStateStore(), Notifiers: []VerdictNotifier{notifier}}
+			tt.execFunc(tt.oldResultDetails, r.State)
 
-			r := MetricesCollecter{}
-			r.GaugeMetrics.InitializeGaugeMetrics()
-			r.GaugeMetrics.unsetOutdatedMetrics(tt.newResultDetails)
+			r.updateVerdictState(tt.newResultDetails)
+
+			result, ok := r.State.Verdict(string(tt.newResultDetails.UID))
+			require.True(t, ok)
+			require.Equal(t, tt.newResultDetails.Verdict, result.Verdict)
+			require.Len(t, notifier.changes, tt.expectedChanges)
+			for _, change := range notifier.changes {
+				require.Equal(t, tt.oldResultDetails.Verdict, change.OldVerdict)
+				require.Equal(t, tt.newResultDetails.Verdict, change.ResultDetails.Verdict)
+			}
 		})
 	}
 
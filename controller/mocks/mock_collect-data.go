@@ -51,6 +51,21 @@ func (mr *MockResultCollectorMockRecorder) GetExperimentMetricsFromResult(arg0,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExperimentMetricsFromResult", reflect.TypeOf((*MockResultCollector)(nil).GetExperimentMetricsFromResult), arg0, arg1)
 }
 
+// GetResult mocks base method.
+func (m *MockResultCollector) GetResult(arg0 clients.ClientSets, arg1, arg2 string) (*v1alpha1.ChaosResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetResult", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v1alpha1.ChaosResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetResult indicates an expected call of GetResult.
+func (mr *MockResultCollectorMockRecorder) GetResult(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResult", reflect.TypeOf((*MockResultCollector)(nil).GetResult), arg0, arg1, arg2)
+}
+
 // GetResultDetails mocks base method.
 func (m *MockResultCollector) GetResultDetails() controller.ChaosResultDetails {
 	m.ctrl.T.Helper()
@@ -65,21 +80,6 @@ func (mr *MockResultCollectorMockRecorder) GetResultDetails() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResultDetails", reflect.TypeOf((*MockResultCollector)(nil).GetResultDetails))
 }
 
-// GetResultList mocks base method.
-func (m *MockResultCollector) GetResultList(arg0 clients.ClientSets, arg1 string, arg2 *controller.MonitoringEnabled) ([]*v1alpha1.ChaosResult, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetResultList", arg0, arg1, arg2)
-	ret0, _ := ret[0].([]*v1alpha1.ChaosResult)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GetResultList indicates an expected call of GetResultList.
-func (mr *MockResultCollectorMockRecorder) GetResultList(arg0, arg1, arg2 interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResultList", reflect.TypeOf((*MockResultCollector)(nil).GetResultList), arg0, arg1, arg2)
-}
-
 // SetResultDetails mocks base method.
 func (m *MockResultCollector) SetResultDetails() {
 	m.ctrl.T.Helper()
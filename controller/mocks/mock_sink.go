@@ -0,0 +1,63 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/litmuschaos/chaos-exporter/controller (interfaces: Sink)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	controller "github.com/litmuschaos/chaos-exporter/controller"
+)
+
+// MockSink is a mock of Sink interface.
+type MockSink struct {
+	ctrl     *gomock.Controller
+	recorder *MockSinkMockRecorder
+}
+
+// MockSinkMockRecorder is the mock recorder for MockSink.
+type MockSinkMockRecorder struct {
+	mock *MockSink
+}
+
+// NewMockSink creates a new mock instance.
+func NewMockSink(ctrl *gomock.Controller) *MockSink {
+	mock := &MockSink{ctrl: ctrl}
+	mock.recorder = &MockSinkMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSink) EXPECT() *MockSinkMockRecorder {
+	return m.recorder
+}
+
+// PublishAggregate mocks base method.
+func (m *MockSink) PublishAggregate(arg0 controller.NamespacedScopeMetrics) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishAggregate", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishAggregate indicates an expected call of PublishAggregate.
+func (mr *MockSinkMockRecorder) PublishAggregate(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishAggregate", reflect.TypeOf((*MockSink)(nil).PublishAggregate), arg0)
+}
+
+// PublishResult mocks base method.
+func (m *MockSink) PublishResult(arg0 controller.ChaosResultDetails) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishResult", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishResult indicates an expected call of PublishResult.
+func (mr *MockSinkMockRecorder) PublishResult(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishResult", reflect.TypeOf((*MockSink)(nil).PublishResult), arg0)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2019 LitmusChaos Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+)
+
+// probeKey identifies a probe of the chaosresult
+type probeKey struct {
+	name, mode string
+}
+
+// probeTransitionKey identifies a probe of the chaosresult along with its new verdict
+type probeTransitionKey struct {
+	probeKey
+	verdict string
+}
+
+// probeTransitions contains the verdict transitions of the probes of a chaosresult
+type probeTransitions struct {
+	// counts contains the number of transitions, keyed by the probe and its new verdict
+	counts map[probeTransitionKey]float64
+	// lastTransitions contains the unix timestamp of the last transition, keyed by the probe
+	lastTransitions map[probeKey]float64
+}
+
+// next returns the transitions along with the probe verdict transitions seen since the last reconcile of the chaosresult
+// the probes which are observed for the first time are not counted as transitions, the receiver is not modified
+func (transitions probeTransitions) next(probeStatuses, oldProbeStatuses []ProbeDetails, now time.Time) probeTransitions {
+	next := probeTransitions{
+		counts:          make(map[probeTransitionKey]float64, len(transitions.counts)),
+		lastTransitions: make(map[probeKey]float64, len(transitions.lastTransitions)),
+	}
+	for key, count := range transitions.counts {
+		next.counts[key] = count
+	}
+	for key, timestamp := range transitions.lastTransitions {
+		next.lastTransitions[key] = timestamp
+	}
+	for _, newProbe := range probeStatuses {
+		for _, oldProbe := range oldProbeStatuses {
+			if oldProbe.Name != newProbe.Name || oldProbe.Verdict == newProbe.Verdict {
+				continue
+			}
+			key := probeKey{name: newProbe.Name, mode: newProbe.Mode}
+			next.counts[probeTransitionKey{probeKey: key, verdict: newProbe.Verdict}]++
+			next.lastTransitions[key] = float64(now.Unix())
+			break
+		}
+	}
+	return next
+}
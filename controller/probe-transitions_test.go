@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testTime is the fixed time used while counting the probe transitions
+var testTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func Test_probeTransitions(t *testing.T) {
+
+	tests := []struct {
+		name                string
+		verdicts            []string
+		expectedTransitions map[string]float64
+	}{
+		{
+			name:                "success: first observation is not a transition",
+			verdicts:            []string{"Awaited"},
+			expectedTransitions: map[string]float64{},
+		},
+		{
+			name:                "success: probe passed",
+			verdicts:            []string{"Awaited", "Passed", "Passed"},
+			expectedTransitions: map[string]float64{"Passed": 1},
+		},
+		{
+			name:                "success: flapping continuous probe",
+			verdicts:            []string{"Awaited", "Passed", "Failed", "Passed", "Failed"},
+			expectedTransitions: map[string]float64{"Passed": 2, "Failed": 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				transitions      probeTransitions
+				oldProbeStatuses []ProbeDetails
+			)
+			for _, verdict := range tt.verdicts {
+				probeStatuses := []ProbeDetails{
+					{Name: "check-frontend", Type: "httpProbe", Mode: "Continuous", Verdict: verdict},
+				}
+				previous := transitions
+				previousCounts := map[probeTransitionKey]float64{}
+				for key, count := range previous.counts {
+					previousCounts[key] = count
+				}
+				transitions = transitions.next(probeStatuses, oldProbeStatuses, testTime)
+				oldProbeStatuses = probeStatuses
+				// the previous transitions are not modified, as those may be rendered by a scrape
+				require.Equal(t, len(previousCounts), len(previous.counts))
+				for key, count := range previousCounts {
+					require.Equal(t, count, previous.counts[key])
+				}
+			}
+
+			require.Len(t, transitions.counts, len(tt.expectedTransitions))
+			for verdict, count := range tt.expectedTransitions {
+				key := probeTransitionKey{probeKey: probeKey{name: "check-frontend", mode: "Continuous"}, verdict: verdict}
+				require.Equal(t, count, transitions.counts[key])
+			}
+			if len(tt.expectedTransitions) == 0 {
+				require.Empty(t, transitions.lastTransitions)
+			} else {
+				require.Equal(t, map[probeKey]float64{
+					{name: "check-frontend", mode: "Continuous"}: float64(testTime.Unix()),
+				}, transitions.lastTransitions)
+			}
+		})
+	}
+}
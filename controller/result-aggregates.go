@@ -0,0 +1,78 @@
+/*
+Copyright 2019 LitmusChaos Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+// ResultAggregates maintains the aggregate metrics of all the chaosresults incrementally,
+// the contribution of a chaosresult is replaced whenever it is processed again
+type ResultAggregates struct {
+	// contributions contains the contribution of every chaosresult, keyed by the chaosresult key
+	contributions map[string]NamespacedScopeMetrics
+	total         NamespacedScopeMetrics
+}
+
+// NewResultAggregates creates the empty ResultAggregates
+func NewResultAggregates() *ResultAggregates {
+	return &ResultAggregates{
+		contributions: map[string]NamespacedScopeMetrics{},
+	}
+}
+
+// Update replaces the contribution of the given chaosresult and returns the updated aggregates
+func (aggregates *ResultAggregates) Update(key string, contribution NamespacedScopeMetrics) NamespacedScopeMetrics {
+	aggregates.Remove(key)
+	aggregates.contributions[key] = contribution
+	aggregates.total = addAggregates(aggregates.total, contribution, 1)
+	return aggregates.total
+}
+
+// Remove removes the contribution of the given chaosresult and returns the updated aggregates
+func (aggregates *ResultAggregates) Remove(key string) NamespacedScopeMetrics {
+	if contribution, ok := aggregates.contributions[key]; ok {
+		delete(aggregates.contributions, key)
+		aggregates.total = addAggregates(aggregates.total, contribution, -1)
+	}
+	return aggregates.total
+}
+
+// Total returns the aggregates of all the chaosresults
+func (aggregates *ResultAggregates) Total() NamespacedScopeMetrics {
+	return aggregates.total
+}
+
+// getResultContribution returns the contribution of the given chaosresult details in the aggregates
+func getResultContribution(resultDetails ChaosResultDetails) NamespacedScopeMetrics {
+	return NamespacedScopeMetrics{
+		PassedExperiments:         resultDetails.PassedExperiments,
+		FailedExperiments:         resultDetails.FailedExperiments,
+		AwaitedExperiments:        resultDetails.AwaitedExperiments,
+		StoppedExperiments:        resultDetails.StoppedExperiments,
+		ExperimentRunCount:        resultDetails.AwaitedExperiments + resultDetails.PassedExperiments + resultDetails.FailedExperiments + resultDetails.StoppedExperiments,
+		ExperimentsInstalledCount: 1,
+	}
+}
+
+// addAggregates adds the given contribution multiplied by the sign into the aggregates
+func addAggregates(total, contribution NamespacedScopeMetrics, sign float64) NamespacedScopeMetrics {
+	return NamespacedScopeMetrics{
+		PassedExperiments:         total.PassedExperiments + sign*contribution.PassedExperiments,
+		FailedExperiments:         total.FailedExperiments + sign*contribution.FailedExperiments,
+		AwaitedExperiments:        total.AwaitedExperiments + sign*contribution.AwaitedExperiments,
+		StoppedExperiments:        total.StoppedExperiments + sign*contribution.StoppedExperiments,
+		ExperimentRunCount:        total.ExperimentRunCount + sign*contribution.ExperimentRunCount,
+		ExperimentsInstalledCount: total.ExperimentsInstalledCount + sign*contribution.ExperimentsInstalledCount,
+	}
+}
@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultAggregates(t *testing.T) {
+	aggregates := NewResultAggregates()
+
+	require.Equal(t, NamespacedScopeMetrics{
+		PassedExperiments:         2,
+		AwaitedExperiments:        1,
+		ExperimentRunCount:        3,
+		ExperimentsInstalledCount: 1,
+	}, aggregates.Update("litmus/result-1", getResultContribution(ChaosResultDetails{PassedExperiments: 2, AwaitedExperiments: 1})))
+
+	require.Equal(t, NamespacedScopeMetrics{
+		PassedExperiments:         2,
+		FailedExperiments:         1,
+		AwaitedExperiments:        1,
+		StoppedExperiments:        1,
+		ExperimentRunCount:        5,
+		ExperimentsInstalledCount: 2,
+	}, aggregates.Update("litmus/result-2", getResultContribution(ChaosResultDetails{FailedExperiments: 1, StoppedExperiments: 1})))
+
+	// the contribution of the updated chaosresult is replaced
+	require.Equal(t, NamespacedScopeMetrics{
+		PassedExperiments:         3,
+		FailedExperiments:         1,
+		StoppedExperiments:        1,
+		ExperimentRunCount:        5,
+		ExperimentsInstalledCount: 2,
+	}, aggregates.Update("litmus/result-1", getResultContribution(ChaosResultDetails{PassedExperiments: 3})))
+
+	// the contribution of the removed chaosresult is subtracted only once
+	require.Equal(t, NamespacedScopeMetrics{
+		PassedExperiments:         3,
+		ExperimentRunCount:        3,
+		ExperimentsInstalledCount: 1,
+	}, aggregates.Remove("litmus/result-2"))
+	require.Equal(t, aggregates.Total(), aggregates.Remove("litmus/result-2"))
+
+	require.Equal(t, NamespacedScopeMetrics{}, aggregates.Remove("litmus/result-1"))
+}
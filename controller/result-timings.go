@@ -0,0 +1,162 @@
+/*
+Copyright 2019 LitmusChaos Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+
+	litmuschaosv1alpha1 "github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// sources of the experiment timings
+const (
+	// TimingSourceEvents is the timing derived from the chaosengine events
+	TimingSourceEvents = "events"
+	// TimingSourceCache is the timing derived from the chaosengine events, which are expired since then
+	TimingSourceCache = "cache"
+	// TimingSourceEngineStatus is the last update time of the experiment inside the chaosengine status
+	TimingSourceEngineStatus = "engine_status"
+	// TimingSourceResultCreation is the creation time of the chaosresult
+	TimingSourceResultCreation = "result_creation"
+	// TimingSourceManagedFields is the last update time of the chaosresult inside its managed fields
+	TimingSourceManagedFields = "managed_fields"
+	// TimingSourceNone is used if the timing is not available from any source
+	TimingSourceNone = "none"
+)
+
+// ResultTimings contains the experiment timings of a chaosresult, which are derived from the chaosengine events
+type ResultTimings struct {
+	StartTime     float64
+	EndTime       float64
+	InjectionTime int64
+}
+
+// TimingSources contains the sources of the experiment timings of a chaosresult
+type TimingSources struct {
+	StartTime     string
+	EndTime       string
+	InjectionTime string
+}
+
+// setTimingFallbacks derive the experiment timings, which are not available inside the chaosengine events
+// the events are garbage collected by kubernetes after a while, so the timings observed earlier are used or
+// derived from the chaosengine and the chaosresult, so that those don't drop to 0 once the events are expired
+func (resultDetails *ChaosResultDetails) setTimingFallbacks(cached ResultTimings, engine *litmuschaosv1alpha1.ChaosEngine, chaosResult *litmuschaosv1alpha1.ChaosResult) *ChaosResultDetails {
+	if resultDetails.TimingSources.StartTime == TimingSourceNone {
+		resultDetails.StartTime, resultDetails.TimingSources.StartTime = getFallbackStartTime(cached, engine, chaosResult, resultDetails.FaultName)
+	}
+	// the timings of the previous run are not used, if the experiment is rerun since then
+	if resultDetails.TimingSources.InjectionTime == TimingSourceNone && cached.InjectionTime != 0 && float64(cached.InjectionTime) >= resultDetails.StartTime {
+		resultDetails.InjectionTime, resultDetails.TimingSources.InjectionTime = cached.InjectionTime, TimingSourceCache
+	}
+	if resultDetails.TimingSources.EndTime == TimingSourceNone {
+		if endTime, source := getFallbackEndTime(cached, engine, chaosResult, resultDetails.FaultName); endTime != 0 && endTime >= resultDetails.StartTime {
+			resultDetails.EndTime, resultDetails.TimingSources.EndTime = endTime, source
+		}
+	}
+	return resultDetails
+}
+
+// getFallbackStartTime derive the start time of the experiment, if the start time is not available inside the chaosengine events
+// it returns the cached start time, the last update time of the running experiment inside the chaosengine status or
+// the creation time of the chaosresult, whichever is available first. The chaosresult is reused across the reruns of
+// the chaosengine, hence its creation time is used only if it contains a single run
+func getFallbackStartTime(cached ResultTimings, engine *litmuschaosv1alpha1.ChaosEngine, chaosResult *litmuschaosv1alpha1.ChaosResult, faultName string) (float64, string) {
+	if cached.StartTime != 0 {
+		return cached.StartTime, TimingSourceCache
+	}
+	for _, experiment := range engine.Status.Experiments {
+		if experiment.Name == faultName && experiment.Status == litmuschaosv1alpha1.ExperimentStatusRunning && !experiment.LastUpdateTime.IsZero() {
+			return float64(experiment.LastUpdateTime.Unix()), TimingSourceEngineStatus
+		}
+	}
+	if getRunCount(chaosResult) == 1 && !chaosResult.CreationTimestamp.IsZero() {
+		return float64(chaosResult.CreationTimestamp.Unix()), TimingSourceResultCreation
+	}
+	return 0, TimingSourceNone
+}
+
+// getRunCount returns the number of the experiment runs recorded inside the chaosresult, including the running one
+func getRunCount(chaosResult *litmuschaosv1alpha1.ChaosResult) int {
+	runs := 0
+	if history := chaosResult.Status.History; history != nil {
+		runs = history.PassedRuns + history.FailedRuns + history.StoppedRuns
+	}
+	switch chaosResult.Status.ExperimentStatus.Phase {
+	case "", litmuschaosv1alpha1.ResultPhaseRunning:
+		runs++
+	}
+	return runs
+}
+
+// getFallbackEndTime derive the end time of the experiment, if the end time is not available inside the chaosengine events
+// it returns the cached end time, the last update time of the completed experiment inside the chaosengine status or
+// the last update time of the completed chaosresult status inside its managed fields, whichever is available first
+func getFallbackEndTime(cached ResultTimings, engine *litmuschaosv1alpha1.ChaosEngine, chaosResult *litmuschaosv1alpha1.ChaosResult, faultName string) (float64, string) {
+	if cached.EndTime != 0 {
+		return cached.EndTime, TimingSourceCache
+	}
+	for _, experiment := range engine.Status.Experiments {
+		if experiment.Name == faultName && experiment.Status == litmuschaosv1alpha1.ExperimentStatusCompleted && !experiment.LastUpdateTime.IsZero() {
+			return float64(experiment.LastUpdateTime.Unix()), TimingSourceEngineStatus
+		}
+	}
+	switch chaosResult.Status.ExperimentStatus.Phase {
+	case "", litmuschaosv1alpha1.ResultPhaseRunning:
+		return 0, TimingSourceNone
+	}
+	var lastUpdateTime int64
+	for _, managedField := range chaosResult.ManagedFields {
+		if managedField.Time != nil && isStatusManagedField(managedField) {
+			lastUpdateTime = maximum(lastUpdateTime, managedField.Time.Unix())
+		}
+	}
+	if lastUpdateTime == 0 {
+		return 0, TimingSourceNone
+	}
+	return float64(lastUpdateTime), TimingSourceManagedFields
+}
+
+// isStatusManagedField checks whether the given managed fields entry owns the chaosresult status
+// the entries of the exporter itself are skipped, as it only updates the metadata of the chaosresult,
+// e.g, the verdict annotations, which doesn't mark the end of the experiment
+func isStatusManagedField(managedField metav1.ManagedFieldsEntry) bool {
+	if managedField.Manager == exporterFieldManager || managedField.FieldsV1 == nil {
+		return false
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(managedField.FieldsV1.Raw, &fields); err != nil {
+		return false
+	}
+	_, ok := fields["f:status"]
+	return ok
+}
+
+// getObservedTimings returns the given cached timings, updated with the timings derived from the chaosengine events
+func (resultDetails *ChaosResultDetails) getObservedTimings(cached ResultTimings) ResultTimings {
+	if resultDetails.TimingSources.StartTime == TimingSourceEvents {
+		cached.StartTime = resultDetails.StartTime
+	}
+	if resultDetails.TimingSources.EndTime == TimingSourceEvents {
+		cached.EndTime = resultDetails.EndTime
+	}
+	if resultDetails.TimingSources.InjectionTime == TimingSourceEvents {
+		cached.InjectionTime = resultDetails.InjectionTime
+	}
+	return cached
+}
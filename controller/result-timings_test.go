@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	litmuschaosv1alpha1 "github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_setTimingFallbacks(t *testing.T) {
+	creationTime := metav1.NewTime(time.Unix(100, 0))
+	updateTime := metav1.NewTime(time.Unix(300, 0))
+	annotateTime := metav1.NewTime(time.Unix(500, 0))
+	statusFields := &metav1.FieldsV1{Raw: []byte(`{"f:status":{"f:experimentStatus":{}}}`)}
+	metadataFields := &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:annotations":{}}}`)}
+
+	tests := map[string]struct {
+		events          corev1.EventList
+		cached          ResultTimings
+		engine          *litmuschaosv1alpha1.ChaosEngine
+		chaosResult     *litmuschaosv1alpha1.ChaosResult
+		expectedTimings ResultTimings
+		expectedSources TimingSources
+		expectedCache   ResultTimings
+	}{
+		"timings derived from the events": {
+			events: corev1.EventList{Items: []corev1.Event{
+				{Reason: "ExperimentDependencyCheck", LastTimestamp: metav1.NewTime(time.Unix(110, 0))},
+				{Reason: "ChaosInject", LastTimestamp: metav1.NewTime(time.Unix(150, 0))},
+				{Reason: "Summary", LastTimestamp: metav1.NewTime(time.Unix(200, 0))},
+			}},
+			cached:          ResultTimings{StartTime: 10, EndTime: 20, InjectionTime: 15},
+			engine:          &litmuschaosv1alpha1.ChaosEngine{},
+			chaosResult:     &litmuschaosv1alpha1.ChaosResult{},
+			expectedTimings: ResultTimings{StartTime: 110, EndTime: 200, InjectionTime: 150},
+			expectedSources: TimingSources{StartTime: TimingSourceEvents, EndTime: TimingSourceEvents, InjectionTime: TimingSourceEvents},
+			expectedCache:   ResultTimings{StartTime: 110, EndTime: 200, InjectionTime: 150},
+		},
+		"timings derived from the cache once the events are expired": {
+			cached:          ResultTimings{StartTime: 110, EndTime: 200, InjectionTime: 150},
+			engine:          &litmuschaosv1alpha1.ChaosEngine{},
+			chaosResult:     &litmuschaosv1alpha1.ChaosResult{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: creationTime}},
+			expectedTimings: ResultTimings{StartTime: 110, EndTime: 200, InjectionTime: 150},
+			expectedSources: TimingSources{StartTime: TimingSourceCache, EndTime: TimingSourceCache, InjectionTime: TimingSourceCache},
+			expectedCache:   ResultTimings{StartTime: 110, EndTime: 200, InjectionTime: 150},
+		},
+		"timings derived from the chaosengine status and the chaosresult creation time": {
+			engine: &litmuschaosv1alpha1.ChaosEngine{Status: litmuschaosv1alpha1.ChaosEngineStatus{
+				Experiments: []litmuschaosv1alpha1.ExperimentStatuses{
+					{Name: "pod-delete", Status: litmuschaosv1alpha1.ExperimentStatusCompleted, LastUpdateTime: updateTime},
+				},
+			}},
+			chaosResult:     &litmuschaosv1alpha1.ChaosResult{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: creationTime}},
+			expectedTimings: ResultTimings{StartTime: 100, EndTime: 300},
+			expectedSources: TimingSources{StartTime: TimingSourceResultCreation, EndTime: TimingSourceEngineStatus, InjectionTime: TimingSourceNone},
+		},
+		"start time derived from the running experiment inside the chaosengine status": {
+			engine: &litmuschaosv1alpha1.ChaosEngine{Status: litmuschaosv1alpha1.ChaosEngineStatus{
+				Experiments: []litmuschaosv1alpha1.ExperimentStatuses{
+					{Name: "pod-delete", Status: litmuschaosv1alpha1.ExperimentStatusRunning, LastUpdateTime: updateTime},
+				},
+			}},
+			chaosResult: &litmuschaosv1alpha1.ChaosResult{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: creationTime},
+				Status: litmuschaosv1alpha1.ChaosResultStatus{
+					ExperimentStatus: litmuschaosv1alpha1.TestStatus{Phase: litmuschaosv1alpha1.ResultPhaseRunning},
+					History:          &litmuschaosv1alpha1.HistoryDetails{PassedRuns: 3},
+				},
+			},
+			expectedTimings: ResultTimings{StartTime: 300},
+			expectedSources: TimingSources{StartTime: TimingSourceEngineStatus, EndTime: TimingSourceNone, InjectionTime: TimingSourceNone},
+		},
+		"creation time of the rerun chaosresult is not used as the start time": {
+			engine: &litmuschaosv1alpha1.ChaosEngine{},
+			chaosResult: &litmuschaosv1alpha1.ChaosResult{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: creationTime},
+				Status: litmuschaosv1alpha1.ChaosResultStatus{
+					ExperimentStatus: litmuschaosv1alpha1.TestStatus{Phase: litmuschaosv1alpha1.ResultPhaseCompleted},
+					History:          &litmuschaosv1alpha1.HistoryDetails{PassedRuns: 1, FailedRuns: 1},
+				},
+			},
+			expectedSources: TimingSources{StartTime: TimingSourceNone, EndTime: TimingSourceNone, InjectionTime: TimingSourceNone},
+		},
+		"end time derived from the status managed fields of the completed chaosresult": {
+			engine: &litmuschaosv1alpha1.ChaosEngine{},
+			chaosResult: &litmuschaosv1alpha1.ChaosResult{
+				ObjectMeta: metav1.ObjectMeta{
+					CreationTimestamp: creationTime,
+					ManagedFields: []metav1.ManagedFieldsEntry{
+						{Manager: "chaos-runner", Time: &creationTime, FieldsV1: statusFields},
+						{Manager: "experiments", Time: &updateTime, FieldsV1: statusFields},
+						// the metadata updates don't mark the end of the experiment
+						{Manager: "kubectl-annotate", Time: &annotateTime, FieldsV1: metadataFields},
+						{Manager: exporterFieldManager, Time: &annotateTime, FieldsV1: statusFields},
+					},
+				},
+				Status: litmuschaosv1alpha1.ChaosResultStatus{
+					ExperimentStatus: litmuschaosv1alpha1.TestStatus{Phase: litmuschaosv1alpha1.ResultPhaseCompleted},
+					History:          &litmuschaosv1alpha1.HistoryDetails{PassedRuns: 1},
+				},
+			},
+			expectedTimings: ResultTimings{StartTime: 100, EndTime: 300},
+			expectedSources: TimingSources{StartTime: TimingSourceResultCreation, EndTime: TimingSourceManagedFields, InjectionTime: TimingSourceNone},
+		},
+		"end time of the running chaosresult is not derived from the managed fields": {
+			engine: &litmuschaosv1alpha1.ChaosEngine{},
+			chaosResult: &litmuschaosv1alpha1.ChaosResult{
+				ObjectMeta: metav1.ObjectMeta{
+					ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "experiments", Time: &updateTime, FieldsV1: statusFields}},
+				},
+				Status: litmuschaosv1alpha1.ChaosResultStatus{
+					ExperimentStatus: litmuschaosv1alpha1.TestStatus{Phase: litmuschaosv1alpha1.ResultPhaseRunning},
+				},
+			},
+			expectedSources: TimingSources{StartTime: TimingSourceNone, EndTime: TimingSourceNone, InjectionTime: TimingSourceNone},
+		},
+		"cached timings of the previous run are not used for the rerun": {
+			events: corev1.EventList{Items: []corev1.Event{
+				{Reason: "ExperimentDependencyCheck", LastTimestamp: metav1.NewTime(time.Unix(400, 0))},
+			}},
+			cached:          ResultTimings{StartTime: 110, EndTime: 200, InjectionTime: 150},
+			engine:          &litmuschaosv1alpha1.ChaosEngine{},
+			chaosResult:     &litmuschaosv1alpha1.ChaosResult{},
+			expectedTimings: ResultTimings{StartTime: 400},
+			expectedSources: TimingSources{StartTime: TimingSourceEvents, EndTime: TimingSourceNone, InjectionTime: TimingSourceNone},
+			expectedCache:   ResultTimings{StartTime: 400, EndTime: 200, InjectionTime: 150},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			resultDetails := ChaosResultDetails{FaultName: "pod-delete"}
+			resultDetails.setStartTime(tt.events).
+				setEndTime(tt.events).
+				setChaosInjectTime(tt.events).
+				setTimingFallbacks(tt.cached, tt.engine, tt.chaosResult).
+				setTotalDuration()
+
+			require.Equal(t, tt.expectedTimings, ResultTimings{
+				StartTime:     resultDetails.StartTime,
+				EndTime:       resultDetails.EndTime,
+				InjectionTime: resultDetails.InjectionTime,
+			})
+			require.Equal(t, tt.expectedSources, resultDetails.TimingSources)
+			require.Equal(t, tt.expectedCache, resultDetails.getObservedTimings(tt.cached))
+			require.Equal(t, math.Max(0, tt.expectedTimings.EndTime-tt.expectedTimings.StartTime), resultDetails.TotalDuration)
+		})
+	}
+}
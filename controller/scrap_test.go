@@ -6,80 +6,293 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/litmuschaos/chaos-exporter/controller"
 	"github.com/litmuschaos/chaos-exporter/controller/mocks"
+	"github.com/litmuschaos/chaos-exporter/pkg/clients"
 	"github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
 )
 
-func TestGetLitmusChaosMetrics(t *testing.T) {
+// newChaosResult creates the chaosresult with the given name and uid
+func newChaosResult(name string, uid types.UID) *v1alpha1.ChaosResult {
+	return &v1alpha1.ChaosResult{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "litmus",
+			UID:       uid,
+		},
+	}
+}
+
+func TestReconcileResult(t *testing.T) {
 	mockCtl := gomock.NewController(t)
 	defer mockCtl.Finish()
 	mockCollectData := mocks.NewMockResultCollector(mockCtl)
+	mockSink := mocks.NewMockSink(mockCtl)
 
 	r := controller.MetricesCollecter{
 		ResultCollector: mockCollectData,
+		Metrics:         controller.NewChaosMetricsCollector(""),
+		Sinks:           []controller.Sink{mockSink},
 	}
+	r.ExporterMetrics.InitializeExporterMetrics(workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()))
 
-	r.GaugeMetrics.InitializeGaugeMetrics().RegisterFixedMetrics()
-
+	// the cases are run in order, the aggregates are carried over from the previous cases
 	tests := []struct {
 		name               string
+		key                string
 		execFunc           func()
 		isErr              bool
-		monitoring         *controller.MonitoringEnabled
-		overallChaosResult []*v1alpha1.ChaosResult
+		expectedAggregates controller.NamespacedScopeMetrics
+		// expectedResults is the number of the chaosresults whose metrics are exported
+		expectedResults int
+		// expectedResultErrors is the number of the skipped chaosresults whose errors are counted
+		expectedResultErrors int
 	}{
 		{
 			name: "success",
+			key:  "litmus/chaosresult-1",
+			execFunc: func() {
+				mockCollectData.EXPECT().GetResult(gomock.Any(), "litmus", "chaosresult-1").
+					Return(newChaosResult("chaosresult-1", "FAKE-UID"), nil).Times(1)
+				mockCollectData.EXPECT().GetExperimentMetricsFromResult(gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+				mockCollectData.EXPECT().SetResultDetails()
+				resultDetails := controller.ChaosResultDetails{
+					Name:              "chaosresult-1",
+					Namespace:         "litmus",
+					UID:               "FAKE-UID",
+					PassedExperiments: 1,
+				}
+				mockCollectData.EXPECT().GetResultDetails().Return(resultDetails).Times(1)
+				mockSink.EXPECT().PublishResult(resultDetails).Return(nil).Times(1)
+			},
+			expectedAggregates: controller.NamespacedScopeMetrics{
+				PassedExperiments:         1,
+				ExperimentRunCount:        1,
+				ExperimentsInstalledCount: 1,
+			},
+			expectedResults: 1,
+		},
+		{
+			name: "success: stopped runs are aggregated",
+			key:  "litmus/chaosresult-3",
 			execFunc: func() {
-				mockCollectData.EXPECT().GetResultList(gomock.Any(), gomock.Any(), gomock.Any()).
-					Return([]*v1alpha1.ChaosResult{
-						{
-							ObjectMeta: metav1.ObjectMeta{
-								Name: "chaosresult-1",
-							},
-						},
-					}, nil).Times(1)
+				mockCollectData.EXPECT().GetResult(gomock.Any(), "litmus", "chaosresult-3").
+					Return(newChaosResult("chaosresult-3", "FAKE-UID-STOPPED"), nil).Times(1)
 				mockCollectData.EXPECT().GetExperimentMetricsFromResult(gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
 				mockCollectData.EXPECT().SetResultDetails()
+				resultDetails := controller.ChaosResultDetails{
+					Name:               "chaosresult-3",
+					Namespace:          "litmus",
+					UID:                "FAKE-UID-STOPPED",
+					PassedExperiments:  1,
+					FailedExperiments:  1,
+					StoppedExperiments: 2,
+				}
+				mockCollectData.EXPECT().GetResultDetails().Return(resultDetails).Times(1)
+				mockSink.EXPECT().PublishResult(resultDetails).Return(nil).Times(1)
+			},
+			expectedAggregates: controller.NamespacedScopeMetrics{
+				PassedExperiments:         2,
+				FailedExperiments:         1,
+				StoppedExperiments:        2,
+				ExperimentRunCount:        5,
+				ExperimentsInstalledCount: 2,
+			},
+			expectedResults: 2,
+		},
+		{
+			name: "success: contribution of the updated chaosresult is replaced",
+			key:  "litmus/chaosresult-1",
+			execFunc: func() {
+				mockCollectData.EXPECT().GetResult(gomock.Any(), "litmus", "chaosresult-1").
+					Return(newChaosResult("chaosresult-1", "FAKE-UID"), nil).Times(1)
+				mockCollectData.EXPECT().GetExperimentMetricsFromResult(gomock.Any(), gomock.Any()).Return(true, nil).Times(1)
+				mockCollectData.EXPECT().SetResultDetails()
 				mockCollectData.EXPECT().GetResultDetails().Return(controller.ChaosResultDetails{
-					UID: "FAKE-UID",
+					Name:               "chaosresult-1",
+					Namespace:          "litmus",
+					UID:                "FAKE-UID",
+					PassedExperiments:  1,
+					FailedExperiments:  1,
+					AwaitedExperiments: 1,
 				}).Times(1)
 			},
-			overallChaosResult: []*v1alpha1.ChaosResult{
-				{
-					ObjectMeta: metav1.ObjectMeta{
-						Name: "chaosresult-1",
-					},
-				},
+			expectedAggregates: controller.NamespacedScopeMetrics{
+				PassedExperiments:         2,
+				FailedExperiments:         2,
+				AwaitedExperiments:        1,
+				StoppedExperiments:        2,
+				ExperimentRunCount:        7,
+				ExperimentsInstalledCount: 2,
+			},
+			expectedResults: 2,
+		},
+		{
+			name: "success: chaosengine without experiments is skipped",
+			key:  "litmus/chaosresult-2",
+			execFunc: func() {
+				mockCollectData.EXPECT().GetResult(gomock.Any(), "litmus", "chaosresult-2").
+					Return(newChaosResult("chaosresult-2", "FAKE-UID-2"), nil).Times(1)
+				mockCollectData.EXPECT().GetExperimentMetricsFromResult(gomock.Any(), gomock.Any()).
+					Return(false, &controller.ResultError{
+						Reason: controller.ResultErrorEngineWithoutExperiments,
+						Err:    &controller.EngineWithoutExperimentsError{EngineName: "engine-2"},
+					}).Times(1)
+				mockCollectData.EXPECT().SetResultDetails()
+				mockCollectData.EXPECT().GetResultDetails().Return(controller.ChaosResultDetails{}).Times(1)
+			},
+			expectedAggregates: controller.NamespacedScopeMetrics{
+				PassedExperiments:         2,
+				FailedExperiments:         2,
+				AwaitedExperiments:        1,
+				StoppedExperiments:        2,
+				ExperimentRunCount:        7,
+				ExperimentsInstalledCount: 2,
+			},
+			expectedResults:      2,
+			expectedResultErrors: 1,
+		},
+		{
+			name: "success: contribution of the deleted chaosresult is removed",
+			key:  "litmus/chaosresult-3",
+			execFunc: func() {
+				mockCollectData.EXPECT().GetResult(gomock.Any(), "litmus", "chaosresult-3").Return(nil, nil).Times(1)
+			},
+			expectedAggregates: controller.NamespacedScopeMetrics{
+				PassedExperiments:         1,
+				FailedExperiments:         1,
+				AwaitedExperiments:        1,
+				ExperimentRunCount:        3,
+				ExperimentsInstalledCount: 1,
+			},
+			expectedResults:      1,
+			expectedResultErrors: 1,
+		},
+		{
+			name: "success: errors of the deleted chaosresult are removed",
+			key:  "litmus/chaosresult-2",
+			execFunc: func() {
+				mockCollectData.EXPECT().GetResult(gomock.Any(), "litmus", "chaosresult-2").Return(nil, nil).Times(1)
+			},
+			expectedAggregates: controller.NamespacedScopeMetrics{
+				PassedExperiments:         1,
+				FailedExperiments:         1,
+				AwaitedExperiments:        1,
+				ExperimentRunCount:        3,
+				ExperimentsInstalledCount: 1,
+			},
+			expectedResults: 1,
+		},
+		{
+			name: "success: metrics of the chaosresult with errors are removed",
+			key:  "litmus/chaosresult-1",
+			execFunc: func() {
+				mockCollectData.EXPECT().GetResult(gomock.Any(), "litmus", "chaosresult-1").
+					Return(newChaosResult("chaosresult-1", "FAKE-UID"), nil).Times(1)
+				mockCollectData.EXPECT().GetExperimentMetricsFromResult(gomock.Any(), gomock.Any()).
+					Return(false, &controller.ResultError{Reason: controller.ResultErrorParse, Err: errors.New("fake parse error")}).Times(1)
+				mockCollectData.EXPECT().SetResultDetails()
+				mockCollectData.EXPECT().GetResultDetails().Return(controller.ChaosResultDetails{}).Times(1)
 			},
-			monitoring: &controller.MonitoringEnabled{},
-			isErr:      false,
+			expectedAggregates:   controller.NamespacedScopeMetrics{},
+			expectedResults:      0,
+			expectedResultErrors: 1,
 		},
 		{
-			name: "failure: no ChaosResultList found",
+			name: "failure: chaosresult not fetched",
+			key:  "litmus/chaosresult-1",
 			execFunc: func() {
-				mockCollectData.EXPECT().GetResultList(gomock.Any(), gomock.Any(), gomock.Any()).
-					Return([]*v1alpha1.ChaosResult{}, errors.New("Fake Error")).Times(1)
+				mockCollectData.EXPECT().GetResult(gomock.Any(), "litmus", "chaosresult-1").
+					Return(nil, errors.New("Fake Error")).Times(1)
 			},
-			overallChaosResult: []*v1alpha1.ChaosResult{},
-			monitoring:         &controller.MonitoringEnabled{},
-			isErr:              true,
+			isErr: true,
+		},
+		{
+			name:     "failure: invalid key",
+			key:      "litmus/chaosresult/1",
+			execFunc: func() {},
+			isErr:    true,
 		},
 	}
+	client := CreateFakeClient(t)
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.execFunc()
 
-			client := CreateFakeClient(t)
-			_, err := r.GetLitmusChaosMetrics(client, &tt.overallChaosResult, tt.monitoring)
+			_, err := r.ReconcileResult(client, tt.key)
 			if tt.isErr {
 				require.Error(t, err)
 				return
 			}
 			require.NoError(t, err)
+			require.Equal(t, tt.expectedAggregates, r.Aggregates.Total())
+			require.Equal(t, tt.expectedResults, testutil.CollectAndCount(r.Metrics, "litmuschaos_passed_experiments"))
+			require.Equal(t, tt.expectedResultErrors, testutil.CollectAndCount(r.ExporterMetrics.ResultErrors))
 		})
 	}
 
+	// the aggregates are published once all the chaosresults are processed
+	mockSink.EXPECT().PublishAggregate(r.Aggregates.Total()).Return(errors.New("fake error")).Times(1)
+	r.PublishAggregates()
+}
+
+func TestGetFailureCategory(t *testing.T) {
+	tests := []struct {
+		errorCode        string
+		expectedCategory string
+	}{
+		{errorCode: "CMD_PROBE_ERROR", expectedCategory: "probe"},
+		{errorCode: "status_checks_error", expectedCategory: "status_checks"},
+		{errorCode: "TARGET_SELECTION_ERROR", expectedCategory: "target_selection"},
+		{errorCode: "CHAOS_INJECT_ERROR", expectedCategory: "chaos_injection"},
+		{errorCode: "CHAOS_REVERT_ERROR", expectedCategory: "chaos_revert"},
+		{errorCode: "HELPER_POD_FAILED_ERROR", expectedCategory: "helper"},
+		{errorCode: "TIMEOUT", expectedCategory: "timeout"},
+		{errorCode: "EXPERIMENT_ABORTED", expectedCategory: "aborted"},
+		{errorCode: "GENERIC_ERROR", expectedCategory: "other"},
+		{errorCode: "", expectedCategory: "other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.errorCode, func(t *testing.T) {
+			require.Equal(t, tt.expectedCategory, controller.GetFailureCategory(tt.errorCode))
+		})
+	}
+}
+
+func TestReconcileRecreatedResult(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+	mockCollectData := mocks.NewMockResultCollector(mockCtl)
+
+	r := controller.MetricesCollecter{
+		ResultCollector: mockCollectData,
+		Metrics:         controller.NewChaosMetricsCollector(""),
+	}
+	reconcile := func(uid types.UID, verdict string) int {
+		mockCollectData.EXPECT().GetResult(gomock.Any(), "litmus", "result").Return(newChaosResult("result", uid), nil).Times(1)
+		mockCollectData.EXPECT().GetExperimentMetricsFromResult(gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+		mockCollectData.EXPECT().SetResultDetails()
+		mockCollectData.EXPECT().GetResultDetails().Return(controller.ChaosResultDetails{
+			Name:          "result",
+			Namespace:     "litmus",
+			UID:           uid,
+			ProbeStatuses: []controller.ProbeDetails{{Name: "check-frontend", Type: "httpProbe", Mode: "Continuous", Verdict: verdict}},
+		}).Times(1)
+		_, err := r.ReconcileResult(clients.ClientSets{}, "litmus/result")
+		require.NoError(t, err)
+		return testutil.CollectAndCount(r.Metrics, "litmuschaos_probe_transitions_total")
+	}
+
+	require.Zero(t, reconcile("UID-1", "Awaited"))
+	require.Equal(t, 1, reconcile("UID-1", "Failed"))
+
+	// the chaosresult recreated with the same name doesn't inherit the transitions of the deleted one
+	require.Zero(t, reconcile("UID-2", "Passed"))
+	require.Zero(t, testutil.CollectAndCount(r.Metrics, "litmuschaos_probe_last_transition_timestamp"))
+
+	require.Equal(t, 1, reconcile("UID-2", "Failed"))
 }
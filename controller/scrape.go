@@ -17,15 +17,13 @@ limitations under the License.
 package controller
 
 import (
-	"fmt"
 	"os"
 	"strings"
 	"time"
 
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/cache"
 
 	"github.com/litmuschaos/chaos-exporter/pkg/clients"
 	"github.com/litmuschaos/chaos-exporter/pkg/log"
@@ -34,226 +32,204 @@ import (
 
 var err error
 
-// GetLitmusChaosMetrics derive and send the chaos metrics
-func (m *MetricesCollecter) GetLitmusChaosMetrics(clients clients.ClientSets, overallChaosResults *[]*litmuschaosv1alpha1.ChaosResult, monitoringEnabled *MonitoringEnabled) (*time.Duration, error) {
-	engineCount := 0
-
-	// initialising the parameters for the namespaced scope metrics
-	namespacedScopeMetrics := NamespacedScopeMetrics{
-		PassedExperiments:         0,
-		FailedExperiments:         0,
-		AwaitedExperiments:        0,
-		ExperimentRunCount:        0,
-		ExperimentsInstalledCount: 0,
-	}
-	// getting all the data required for aws configuration
-	awsConfig := AWSConfig{
-		Namespace:   os.Getenv("AWS_CLOUDWATCH_METRIC_NAMESPACE"),
-		ClusterName: os.Getenv("CLUSTER_NAME"),
-		Service:     os.Getenv("APP_NAME"),
+// ReconcileResult derive the chaos metrics of the chaosresult with the given key and send those to the sinks
+// it updates the aggregate metrics with the contribution of the given chaosresult, the updated metrics
+// are exported together once the chaosresult is processed
+func (m *MetricesCollecter) ReconcileResult(clients clients.ClientSets, key string) (*time.Duration, error) {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return nil, err
 	}
-	watchNamespace := os.Getenv("WATCH_NAMESPACE")
-	// Getting list of all the chaosresults for the monitoring
-	resultList, err := m.ResultCollector.GetResultList(clients, watchNamespace, monitoringEnabled)
+	chaosresult, err := m.ResultCollector.GetResult(clients, namespace, name)
 	if err != nil {
 		return nil, err
 	}
-	// unset the metrics correspond to deleted chaosresults
-	m.GaugeMetrics.unsetDeletedChaosResults(*overallChaosResults, resultList)
-	// updating the overall chaosresults items to latest
-	*overallChaosResults = resultList
-
-	var needRequeue *time.Duration
-
-	// iterating over all chaosresults and derive all the metrics data it generates metrics per chaosresult
-	// and aggregate metrics of all results present inside chaos namespace, if chaos namespace is defined
-	// otherwise it derive metrics for all chaosresults present inside cluster
-	for _, chaosresult := range resultList {
-
-		m.ResultCollector.SetResultDetails()
-		// deriving metrics data from the chaosresult
-		skip, err := m.ResultCollector.GetExperimentMetricsFromResult(chaosresult, clients)
-		resultDetails := m.ResultCollector.GetResultDetails()
-		if err != nil {
-			return nil, err
-		}
-		// generating the aggeregate metrics from per chaosresult metric
-		namespacedScopeMetrics.AwaitedExperiments += resultDetails.AwaitedExperiments
-		namespacedScopeMetrics.PassedExperiments += resultDetails.PassedExperiments
-		namespacedScopeMetrics.FailedExperiments += resultDetails.FailedExperiments
-		namespacedScopeMetrics.ExperimentsInstalledCount++
-		namespacedScopeMetrics.ExperimentRunCount += resultDetails.AwaitedExperiments + resultDetails.PassedExperiments + resultDetails.FailedExperiments
-		// skipping exporting metrics for the results, whose chaosengine is either completed or not exist
-		if skip {
-			continue
-		}
-		//engineCount is storing count of chaosengines
-		//It is helping in keeping track of available chaosengines associated with chaosresults
-		engineCount++
-
-		//DISPLAY THE METRICS INFORMATION
-		log.InfoWithValues("The chaos metrics are as follows", logrus.Fields{
-			"ResultName":             resultDetails.Name,
-			"ResultNamespace":        resultDetails.Namespace,
-			"PassedExperiments":      resultDetails.PassedExperiments,
-			"FailedExperiments":      resultDetails.FailedExperiments,
-			"AwaitedExperiments":     resultDetails.AwaitedExperiments,
-			"ProbeSuccessPercentage": resultDetails.ProbeSuccessPercentage,
-			"StartTime":              resultDetails.StartTime,
-			"EndTime":                resultDetails.EndTime,
-			"ChaosInjectTime":        resultDetails.InjectionTime,
-			"TotalDuration":          resultDetails.TotalDuration,
-			"ResultVerdict":          resultDetails.Verdict,
-			"FaultName":              resultDetails.FaultName,
-		})
-
-		// setting chaosresult metrics for the given chaosresult
-		verdictValue, requeue := m.GaugeMetrics.unsetOutdatedMetrics(resultDetails)
-		if requeue != nil {
-			needRequeue = requeue
-		}
-		m.GaugeMetrics.setResultChaosMetrics(resultDetails, verdictValue)
-		// setting chaosresult aws metrics for the given chaosresult, which can be used for cloudwatch
-		if awsConfig.Namespace != "" && awsConfig.ClusterName != "" && awsConfig.Service != "" {
-			awsConfig.setAwsResultChaosMetrics(resultDetails)
-		}
+	// prune the state of the deleted chaosresult or the chaosresult which is recreated with the same name,
+	// its metrics are removed from the snapshot along with it
+	deleted := false
+	if oldResult, ok := m.results[key]; ok && (chaosresult == nil || oldResult.UID != chaosresult.UID) {
+		m.stateStore().Delete(string(oldResult.UID))
+		delete(m.results, key)
+		deleted = true
+	}
+	if chaosresult == nil {
+		m.ExporterMetrics.unsetResultErrors(namespace, name)
+		m.swapSnapshot(key, deleted, nil, m.resultAggregates().Remove(key))
+		return nil, nil
+	}
+	if m.results == nil {
+		m.results = map[string]*litmuschaosv1alpha1.ChaosResult{}
 	}
-	if engineCount == 0 {
-		if monitoringEnabled.IsChaosEnginesAvailable && monitoringEnabled.IsChaosResultsAvailable {
-			monitoringEnabled.IsChaosEnginesAvailable = false
-			log.Info("[Wait]: Hold on, no active chaosengine found ... ")
+	m.results[key] = chaosresult
+
+	m.ResultCollector.SetResultDetails()
+	// deriving metrics data from the chaosresult
+	skip, err := m.ResultCollector.GetExperimentMetricsFromResult(chaosresult, clients)
+	resultDetails := m.ResultCollector.GetResultDetails()
+	if err != nil {
+		// skipping the chaosresults whose metrics can't be derived, e.g, the chaosengine doesn't contain any experiment
+		// only the errors of the informer caches are returned, as those affect all the chaosresults
+		// the metrics of the skipped chaosresult are removed along with its contribution to the aggregates
+		var resultErr *ResultError
+		if errors.As(err, &resultErr) {
+			log.Warnf("Skipping chaosresult %s/%s, err: %v", chaosresult.Namespace, chaosresult.Name, err)
+			m.ExporterMetrics.recordResultError(chaosresult.Namespace, chaosresult.Name, resultErr.Reason)
+			m.swapSnapshot(key, true, nil, m.resultAggregates().Remove(key))
+			return nil, nil
 		}
+		return nil, err
 	}
-	if !monitoringEnabled.IsChaosEnginesAvailable && engineCount != 0 {
-		monitoringEnabled.IsChaosEnginesAvailable = true
+	// updating the aggregate metrics with the contribution of the chaosresult
+	aggregates := m.resultAggregates().Update(key, getResultContribution(resultDetails))
+	// skipping exporting metrics for the results, whose chaosengine is either completed or not exist
+	if skip {
+		m.swapSnapshot(key, deleted, nil, aggregates)
+		return nil, nil
 	}
 
-	//setting aggregate metrics from the all chaosresults
-	m.GaugeMetrics.setNamespacedChaosMetrics(namespacedScopeMetrics, watchNamespace)
-	//setting aggregate aws metrics from the all chaosresults, which can be used for cloudwatch
-	if awsConfig.Namespace != "" && awsConfig.ClusterName != "" && awsConfig.Service != "" {
-		awsConfig.setAwsNamespacedChaosMetrics(namespacedScopeMetrics)
+	//DISPLAY THE METRICS INFORMATION
+	log.InfoWithValues("The chaos metrics are as follows", logrus.Fields{
+		"ResultName":             resultDetails.Name,
+		"ResultNamespace":        resultDetails.Namespace,
+		"PassedExperiments":      resultDetails.PassedExperiments,
+		"FailedExperiments":      resultDetails.FailedExperiments,
+		"AwaitedExperiments":     resultDetails.AwaitedExperiments,
+		"StoppedExperiments":     resultDetails.StoppedExperiments,
+		"ProbeSuccessPercentage": resultDetails.ProbeSuccessPercentage,
+		"StartTime":              resultDetails.StartTime,
+		"EndTime":                resultDetails.EndTime,
+		"ChaosInjectTime":        resultDetails.InjectionTime,
+		"TotalDuration":          resultDetails.TotalDuration,
+		"ResultVerdict":          resultDetails.Verdict,
+		"FaultName":              resultDetails.FaultName,
+	})
+
+	// count the probe verdict transitions seen since the last reconcile, the transitions
+	// of the deleted chaosresult are not carried over to the recreated one
+	oldResult, _ := m.stateStore().Verdict(string(resultDetails.UID))
+	transitions := m.chaosMetrics().load().results[key].transitions
+	if deleted {
+		transitions = probeTransitions{}
 	}
+	transitions = transitions.next(resultDetails.ProbeStatuses, oldResult.ProbeStatuses, time.Now())
+	verdictValue, needRequeue := m.updateVerdictState(resultDetails)
+	m.swapSnapshot(key, deleted, &resultSnapshot{
+		details:      resultDetails,
+		verdictValue: verdictValue,
+		transitions:  transitions,
+	}, aggregates)
+	// publishing the chaosresult metrics to the enabled sinks
+	m.publishResult(resultDetails)
 	return needRequeue, nil
 }
 
-// setNamespacedChaosMetrics sets metrics for the all chaosresults
-func (gaugeMetrics *GaugeMetrics) setNamespacedChaosMetrics(namespacedScopeMetrics NamespacedScopeMetrics, watchNamespace string) {
-	switch watchNamespace {
-	case "":
-		gaugeMetrics.ClusterScopedTotalAwaitedExperiments.WithLabelValues().Set(namespacedScopeMetrics.AwaitedExperiments)
-		gaugeMetrics.ClusterScopedTotalPassedExperiments.WithLabelValues().Set(namespacedScopeMetrics.PassedExperiments)
-		gaugeMetrics.ClusterScopedTotalFailedExperiments.WithLabelValues().Set(namespacedScopeMetrics.FailedExperiments)
-		gaugeMetrics.ClusterScopedExperimentsRunCount.WithLabelValues().Set(namespacedScopeMetrics.ExperimentRunCount)
-		gaugeMetrics.ClusterScopedExperimentsInstalledCount.WithLabelValues().Set(namespacedScopeMetrics.ExperimentsInstalledCount)
-	default:
-		gaugeMetrics.NamespaceScopedTotalAwaitedExperiments.WithLabelValues(watchNamespace).Set(namespacedScopeMetrics.AwaitedExperiments)
-		gaugeMetrics.NamespaceScopedTotalPassedExperiments.WithLabelValues(watchNamespace).Set(namespacedScopeMetrics.PassedExperiments)
-		gaugeMetrics.NamespaceScopedTotalFailedExperiments.WithLabelValues(watchNamespace).Set(namespacedScopeMetrics.FailedExperiments)
-		gaugeMetrics.NamespaceScopedExperimentsRunCount.WithLabelValues(watchNamespace).Set(namespacedScopeMetrics.ExperimentRunCount)
-		gaugeMetrics.NamespaceScopedExperimentsInstalledCount.WithLabelValues(watchNamespace).Set(namespacedScopeMetrics.ExperimentsInstalledCount)
-	}
+// swapSnapshot swaps the snapshot with the one containing the changes of the given chaosresult and the aggregates
+// the metrics of the deleted chaosresult are removed, the metrics of the chaosresult are replaced if provided
+func (m *MetricesCollecter) swapSnapshot(key string, deleted bool, result *resultSnapshot, aggregates NamespacedScopeMetrics) {
+	m.chaosMetrics().update(func(snapshot *metricsSnapshot) {
+		if deleted {
+			delete(snapshot.results, key)
+		}
+		if result != nil {
+			snapshot.results[key] = *result
+		}
+		snapshot.aggregates = aggregates
+	})
 }
 
-// setResultChaosMetrics sets metrics for the given chaosresult details
-func (gaugeMetrics *GaugeMetrics) setResultChaosMetrics(resultDetails ChaosResultDetails, verdictValue float64) {
+// PublishAggregates publishes the aggregate metrics of all the chaosresults to the enabled sinks
+// and sends the metrics buffered by the sinks
+func (m *MetricesCollecter) PublishAggregates() {
+	m.publishAggregate(m.resultAggregates().Total())
+	m.flushSinks()
+}
 
-	gaugeMetrics.ResultAwaitedExperiments.WithLabelValues(resultDetails.Namespace, resultDetails.Name, resultDetails.ChaosEngineName, resultDetails.ChaosEngineContext, resultDetails.WorkflowName, resultDetails.FaultName).Set(resultDetails.AwaitedExperiments)
-	gaugeMetrics.ResultPassedExperiments.WithLabelValues(resultDetails.Namespace, resultDetails.Name, resultDetails.ChaosEngineName, resultDetails.ChaosEngineContext, resultDetails.FaultName).Set(resultDetails.PassedExperiments)
-	gaugeMetrics.ResultFailedExperiments.WithLabelValues(resultDetails.Namespace, resultDetails.Name, resultDetails.ChaosEngineName, resultDetails.ChaosEngineContext, resultDetails.FaultName).Set(resultDetails.FailedExperiments)
-	gaugeMetrics.ResultProbeSuccessPercentage.WithLabelValues(resultDetails.Namespace, resultDetails.Name, resultDetails.ChaosEngineName, resultDetails.ChaosEngineContext, resultDetails.FaultName).Set(resultDetails.ProbeSuccessPercentage)
-	switch strings.ToLower(resultDetails.Verdict) {
-	case "awaited":
-		gaugeMetrics.ResultVerdict.WithLabelValues(resultDetails.Namespace, resultDetails.Name, resultDetails.ChaosEngineName, resultDetails.ChaosEngineContext, resultDetails.Verdict, fmt.Sprintf("%f", resultDetails.ProbeSuccessPercentage),
-			resultDetails.AppLabel, resultDetails.AppNs, resultDetails.AppKind, resultDetails.WorkflowName, resultDetails.FaultName).Set(float64(0))
-	default:
-		gaugeMetrics.ResultVerdict.WithLabelValues(resultDetails.Namespace, resultDetails.Name, resultDetails.ChaosEngineName, resultDetails.ChaosEngineContext, resultDetails.Verdict, fmt.Sprintf("%f", resultDetails.ProbeSuccessPercentage),
-			resultDetails.AppLabel, resultDetails.AppNs, resultDetails.AppKind, resultDetails.WorkflowName, resultDetails.FaultName).Set(verdictValue)
+// chaosMetrics returns the chaos metrics collector, it creates a new one if not provided
+func (m *MetricesCollecter) chaosMetrics() *ChaosMetricsCollector {
+	if m.Metrics == nil {
+		m.Metrics = NewChaosMetricsCollector(os.Getenv("WATCH_NAMESPACE"))
 	}
-	gaugeMetrics.ExperimentStartTime.WithLabelValues(resultDetails.Namespace, resultDetails.Name, resultDetails.ChaosEngineName, resultDetails.ChaosEngineContext, resultDetails.FaultName).Set(resultDetails.StartTime)
-	gaugeMetrics.ExperimentEndTime.WithLabelValues(resultDetails.Namespace, resultDetails.Name, resultDetails.ChaosEngineName, resultDetails.ChaosEngineContext, resultDetails.FaultName).Set(resultDetails.EndTime)
-	gaugeMetrics.ExperimentChaosInjectedTime.WithLabelValues(resultDetails.Namespace, resultDetails.Name, resultDetails.ChaosEngineName, resultDetails.ChaosEngineContext, resultDetails.FaultName).Set(float64(resultDetails.InjectionTime))
-	gaugeMetrics.ExperimentTotalDuration.WithLabelValues(resultDetails.Namespace, resultDetails.Name, resultDetails.ChaosEngineName, resultDetails.ChaosEngineContext, resultDetails.FaultName).Set(resultDetails.TotalDuration)
+	return m.Metrics
 }
 
-// unsetResultChaosMetrics unset metrics for the given chaosresult details
-func (gaugeMetrics *GaugeMetrics) unsetResultChaosMetrics(resultDetails *ChaosResultDetails) {
-	gaugeMetrics.ResultAwaitedExperiments.DeleteLabelValues(resultDetails.Namespace, resultDetails.Name, resultDetails.ChaosEngineName, resultDetails.ChaosEngineContext, resultDetails.WorkflowName, resultDetails.FaultName)
-	gaugeMetrics.ResultPassedExperiments.DeleteLabelValues(resultDetails.Namespace, resultDetails.Name, resultDetails.ChaosEngineName, resultDetails.ChaosEngineContext, resultDetails.FaultName)
-	gaugeMetrics.ResultFailedExperiments.DeleteLabelValues(resultDetails.Namespace, resultDetails.Name, resultDetails.ChaosEngineName, resultDetails.ChaosEngineContext, resultDetails.FaultName)
-	gaugeMetrics.ResultProbeSuccessPercentage.DeleteLabelValues(resultDetails.Namespace, resultDetails.Name, resultDetails.ChaosEngineName, resultDetails.ChaosEngineContext, resultDetails.FaultName)
-	gaugeMetrics.ResultVerdict.DeleteLabelValues(resultDetails.Namespace, resultDetails.Name, resultDetails.ChaosEngineName, resultDetails.ChaosEngineContext, resultDetails.Verdict,
-		fmt.Sprintf("%f", resultDetails.ProbeSuccessPercentage), resultDetails.AppLabel, resultDetails.AppNs, resultDetails.AppKind, resultDetails.WorkflowName, resultDetails.FaultName)
-	gaugeMetrics.ExperimentStartTime.DeleteLabelValues(resultDetails.Namespace, resultDetails.Name, resultDetails.ChaosEngineName, resultDetails.ChaosEngineContext, resultDetails.FaultName)
-	gaugeMetrics.ExperimentEndTime.DeleteLabelValues(resultDetails.Namespace, resultDetails.Name, resultDetails.ChaosEngineName, resultDetails.ChaosEngineContext, resultDetails.FaultName)
-	gaugeMetrics.ExperimentChaosInjectedTime.DeleteLabelValues(resultDetails.Namespace, resultDetails.Name, resultDetails.ChaosEngineName, resultDetails.ChaosEngineContext, resultDetails.FaultName)
-	gaugeMetrics.ExperimentTotalDuration.DeleteLabelValues(resultDetails.Namespace, resultDetails.Name, resultDetails.ChaosEngineName, resultDetails.ChaosEngineContext, resultDetails.FaultName)
+// resultAggregates returns the aggregates, it creates the empty aggregates if not provided
+func (m *MetricesCollecter) resultAggregates() *ResultAggregates {
+	if m.Aggregates == nil {
+		m.Aggregates = NewResultAggregates()
+	}
+	return m.Aggregates
 }
 
-// setAwsResultChaosMetrics sets aws metrics for the given chaosresult
-func (awsConfig *AWSConfig) setAwsResultChaosMetrics(resultDetails ChaosResultDetails) {
-	sess := session.Must(session.NewSessionWithOptions(session.Options{
-		SharedConfigState: session.SharedConfigEnable,
-	}))
-	awsConfig.putAwsMetricData(sess, "chaosresult_passed_experiments", "Count", resultDetails.PassedExperiments)
-	awsConfig.putAwsMetricData(sess, "chaosresult_failed_experiments", "Count", resultDetails.FailedExperiments)
-	awsConfig.putAwsMetricData(sess, "chaosresult_awaited_experiments", "Count", resultDetails.AwaitedExperiments)
-	awsConfig.putAwsMetricData(sess, "chaosresult_probe_success_percentage", "Count", resultDetails.ProbeSuccessPercentage)
-	awsConfig.putAwsMetricData(sess, "chaosresult_start_time", "Count", resultDetails.StartTime)
-	awsConfig.putAwsMetricData(sess, "chaosresult_end_time", "Count", resultDetails.EndTime)
-	awsConfig.putAwsMetricData(sess, "chaosresult_inject_time", "Count", float64(resultDetails.InjectionTime))
-	awsConfig.putAwsMetricData(sess, "chaosresult_total_duration", "Count", resultDetails.TotalDuration)
+// getResultUIDs returns the uids of the given chaosresults
+func getResultUIDs(resultList []*litmuschaosv1alpha1.ChaosResult) map[string]bool {
+	uids := make(map[string]bool, len(resultList))
+	for _, chaosresult := range resultList {
+		uids[string(chaosresult.UID)] = true
+	}
+	return uids
 }
 
-// setAwsNamespacedChaosMetrics sets aws metrics for all chaosresults
-func (awsConfig *AWSConfig) setAwsNamespacedChaosMetrics(namespacedScopeMetrics NamespacedScopeMetrics) {
-	sess := session.Must(session.NewSessionWithOptions(session.Options{
-		SharedConfigState: session.SharedConfigEnable,
-	}))
-	awsConfig.putAwsMetricData(sess, "total_passed_experiments", "Count", namespacedScopeMetrics.PassedExperiments)
-	awsConfig.putAwsMetricData(sess, "total_failed_experiments", "Count", namespacedScopeMetrics.FailedExperiments)
-	awsConfig.putAwsMetricData(sess, "total_awaited_experiments", "Count", namespacedScopeMetrics.AwaitedExperiments)
-	awsConfig.putAwsMetricData(sess, "experiments_run_count", "Count", namespacedScopeMetrics.ExperimentRunCount)
-	awsConfig.putAwsMetricData(sess, "experiments_installed_count", "Count", namespacedScopeMetrics.ExperimentsInstalledCount)
+// isFailedVerdict checks whether the given chaosresult verdict is failed
+func isFailedVerdict(verdict string) bool {
+	return strings.EqualFold(verdict, string(litmuschaosv1alpha1.ResultVerdictFailed))
 }
 
-// putAwsMetricData put the metrics data in cloudwatch service
-func (awsConfig *AWSConfig) putAwsMetricData(sess *session.Session, metricName string, unit string, value float64) error {
-	dimension1 := "ClusterName"
-	dimension2 := "Service"
-	// Create new Amazon CloudWatch client
-	svc := cloudwatch.New(sess)
-
-	if awsConfig.Namespace == "" || awsConfig.ClusterName == "" || awsConfig.Service == "" {
-		return errors.Errorf("You must supply a namespace, clusterName and serviceName values")
+// getErrorCodeLabel returns the normalized error code, unknown if the error code is not provided
+func getErrorCodeLabel(errorCode string) string {
+	if errorCode = strings.TrimSpace(errorCode); errorCode == "" {
+		return "unknown"
 	}
+	return strings.ToUpper(errorCode)
+}
 
-	log.Infof("Putting new AWS metric: Namespace %v, Metric %v", awsConfig.Namespace, metricName)
+// getFailureCategory returns the coarse category of the failure for the given error code
+// the error codes are defined by the litmus-go experiments, e.g, STATUS_CHECKS_ERROR, HTTP_PROBE_ERROR
+func getFailureCategory(errorCode string) string {
+	errorCode = getErrorCodeLabel(errorCode)
+	switch {
+	case strings.HasSuffix(errorCode, "_PROBE_ERROR"):
+		return "probe"
+	case errorCode == "STATUS_CHECKS_ERROR":
+		return "status_checks"
+	case errorCode == "TARGET_SELECTION_ERROR":
+		return "target_selection"
+	case errorCode == "CHAOS_INJECT_ERROR", errorCode == "CONTAINER_RUNTIME_ERROR":
+		return "chaos_injection"
+	case errorCode == "CHAOS_REVERT_ERROR":
+		return "chaos_revert"
+	case strings.HasPrefix(errorCode, "HELPER"):
+		return "helper"
+	case errorCode == "TIMEOUT":
+		return "timeout"
+	case errorCode == "EXPERIMENT_ABORTED":
+		return "aborted"
+	default:
+		return "other"
+	}
+}
 
-	_, err := svc.PutMetricData(&cloudwatch.PutMetricDataInput{
-		Namespace: &awsConfig.Namespace,
-		MetricData: []*cloudwatch.MetricDatum{
-			{
-				MetricName: &metricName,
-				Unit:       &unit,
-				Value:      &value,
-				Dimensions: []*cloudwatch.Dimension{
-					{
-						Name:  &dimension1,
-						Value: &awsConfig.ClusterName,
-					},
-					{
-						Name:  &dimension2,
-						Value: &awsConfig.Service,
-					},
-				},
-			},
-		},
-	})
-	if err != nil {
-		log.Errorf("Error during putting metrics to CloudWatch: %v", err)
-		return err
+// getTargetChaosStatusValue returns the metric value for the given target chaos status
+func getTargetChaosStatusValue(chaosStatus string) float64 {
+	switch strings.ToLower(chaosStatus) {
+	case "targeted":
+		return 0
+	case "injected":
+		return 1
+	case "reverted":
+		return 2
+	default:
+		return -1
 	}
+}
 
-	return nil
+// getProbeStatusValue returns the metric value for the given probe verdict
+func getProbeStatusValue(verdict string) float64 {
+	switch litmuschaosv1alpha1.ProbeVerdict(verdict) {
+	case litmuschaosv1alpha1.ProbeVerdictPassed:
+		return 1
+	case litmuschaosv1alpha1.ProbeVerdictFailed:
+		return 0
+	default:
+		return -1
+	}
 }
@@ -0,0 +1,137 @@
+/*
+Copyright 2019 LitmusChaos Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate mockgen -destination=mocks/mock_sink.go -package=mocks github.com/litmuschaos/chaos-exporter/controller Sink
+package controller
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/log"
+)
+
+// Sink publishes the chaos metrics to an external backend, in addition to the prometheus metrics
+type Sink interface {
+	// PublishResult publishes the metrics of the given chaosresult
+	PublishResult(resultDetails ChaosResultDetails) error
+	// PublishAggregate publishes the aggregated metrics of all the chaosresults
+	PublishAggregate(namespacedScopeMetrics NamespacedScopeMetrics) error
+}
+
+// Flusher is implemented by the sinks which buffer the published metrics
+// the sinks are flushed at the end of every metrics collection
+type Flusher interface {
+	Flush() error
+}
+
+// SinkFactory creates the sink from its configuration
+type SinkFactory func() (Sink, error)
+
+// SinkRegistry contains the factories of the supported sinks, keyed by the sink name
+type SinkRegistry struct {
+	factories map[string]SinkFactory
+}
+
+// NewSinkRegistry creates the sink registry containing the built-in sinks
+func NewSinkRegistry() *SinkRegistry {
+	registry := &SinkRegistry{
+		factories: map[string]SinkFactory{},
+	}
+	registry.Register("cloudwatch", NewCloudWatchSinkFromEnv)
+	return registry
+}
+
+// Register adds the factory of the given sink, it replaces the existing factory with the same name
+func (registry *SinkRegistry) Register(name string, factory SinkFactory) {
+	registry.factories[name] = factory
+}
+
+// Build creates the sinks with the given names
+func (registry *SinkRegistry) Build(names []string) ([]Sink, error) {
+	var sinks []Sink
+	for _, name := range names {
+		factory, ok := registry.factories[name]
+		if !ok {
+			return nil, errors.Errorf("unsupported sink: %s, supported values: %s", name, strings.Join(registry.names(), ", "))
+		}
+		sink, err := factory()
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to create the %s sink", name)
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+// BuildFromEnv creates the sinks enabled by the comma separated METRICS_SINKS ENV
+// the cloudwatch sink is enabled if METRICS_SINKS is not provided and the aws ENVs are provided
+func (registry *SinkRegistry) BuildFromEnv() ([]Sink, error) {
+	var names []string
+	for _, name := range strings.Split(os.Getenv("METRICS_SINKS"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 && getAWSConfigFromEnv().isValid() {
+		names = append(names, "cloudwatch")
+	}
+	return registry.Build(names)
+}
+
+// names returns the sorted names of the registered sinks
+func (registry *SinkRegistry) names() []string {
+	names := make([]string, 0, len(registry.factories))
+	for name := range registry.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// publishResult publishes the metrics of the given chaosresult to all the sinks
+// the sink failures are logged, they don't fail the metrics collection
+func (m *MetricesCollecter) publishResult(resultDetails ChaosResultDetails) {
+	for _, sink := range m.Sinks {
+		if err := sink.PublishResult(resultDetails); err != nil {
+			log.Errorf("Unable to publish the metrics of chaosresult %s/%s, err: %v", resultDetails.Namespace, resultDetails.Name, err)
+		}
+	}
+}
+
+// publishAggregate publishes the aggregated metrics to all the sinks
+// the sink failures are logged, they don't fail the metrics collection
+func (m *MetricesCollecter) publishAggregate(namespacedScopeMetrics NamespacedScopeMetrics) {
+	for _, sink := range m.Sinks {
+		if err := sink.PublishAggregate(namespacedScopeMetrics); err != nil {
+			log.Errorf("Unable to publish the aggregated metrics, err: %v", err)
+		}
+	}
+}
+
+// flushSinks sends the metrics buffered by the sinks
+func (m *MetricesCollecter) flushSinks() {
+	for _, sink := range m.Sinks {
+		if flusher, ok := sink.(Flusher); ok {
+			if err := flusher.Flush(); err != nil {
+				log.Errorf("Unable to flush the metrics, err: %v", err)
+			}
+		}
+	}
+}
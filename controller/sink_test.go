@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSink records the published metrics
+type fakeSink struct {
+	results    []ChaosResultDetails
+	aggregates []NamespacedScopeMetrics
+	err        error
+}
+
+func (sink *fakeSink) PublishResult(resultDetails ChaosResultDetails) error {
+	sink.results = append(sink.results, resultDetails)
+	return sink.err
+}
+
+func (sink *fakeSink) PublishAggregate(namespacedScopeMetrics NamespacedScopeMetrics) error {
+	sink.aggregates = append(sink.aggregates, namespacedScopeMetrics)
+	return sink.err
+}
+
+func TestSinkRegistryBuildFromEnv(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           map[string]string
+		expectedSinks int
+		isErr         bool
+	}{
+		{
+			name:          "success: no sink enabled",
+			expectedSinks: 0,
+		},
+		{
+			name: "success: cloudwatch enabled by the aws ENVs",
+			env: map[string]string{
+				"AWS_CLOUDWATCH_METRIC_NAMESPACE": "litmus",
+				"CLUSTER_NAME":                    "cluster",
+				"APP_NAME":                        "app",
+			},
+			expectedSinks: 1,
+		},
+		{
+			name: "success: custom sink enabled",
+			env: map[string]string{
+				"METRICS_SINKS": " fake ,",
+			},
+			expectedSinks: 1,
+		},
+		{
+			name: "failure: cloudwatch enabled without the aws ENVs",
+			env: map[string]string{
+				"METRICS_SINKS": "cloudwatch",
+			},
+			isErr: true,
+		},
+		{
+			name: "failure: unsupported sink",
+			env: map[string]string{
+				"METRICS_SINKS": "fake,unknown",
+			},
+			isErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"METRICS_SINKS", "AWS_CLOUDWATCH_METRIC_NAMESPACE", "CLUSTER_NAME", "APP_NAME"} {
+				t.Setenv(key, tt.env[key])
+			}
+			registry := NewSinkRegistry()
+			registry.Register("fake", func() (Sink, error) { return &fakeSink{}, nil })
+
+			sinks, err := registry.BuildFromEnv()
+			if tt.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, sinks, tt.expectedSinks)
+		})
+	}
+}
+
+func TestPublishToSinks(t *testing.T) {
+	failingSink := &fakeSink{err: errors.New("fake error")}
+	sink := &fakeSink{}
+	m := MetricesCollecter{
+		Sinks: []Sink{failingSink, sink},
+	}
+
+	m.publishResult(ChaosResultDetails{Name: "chaosresult-1"})
+	m.publishAggregate(NamespacedScopeMetrics{PassedExperiments: 1})
+
+	// the failure of a sink doesn't stop publishing to the other sinks
+	for _, s := range []*fakeSink{failingSink, sink} {
+		require.Equal(t, []ChaosResultDetails{{Name: "chaosresult-1"}}, s.results)
+		require.Equal(t, []NamespacedScopeMetrics{{PassedExperiments: 1}}, s.aggregates)
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2019 LitmusChaos Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// stateKey is the key under which the state is stored inside the configmap
+const stateKey = "state.json"
+
+// StateBackend persists the verdict details of the chaosresults across the exporter restarts
+type StateBackend interface {
+	// Load returns the persisted verdict details keyed by the chaosresult uid
+	Load() (map[string]ResultData, error)
+	// Save persists the given verdict details
+	Save(verdicts map[string]ResultData) error
+}
+
+// NewStateBackendFromEnv creates the state backend based on the STATE_BACKEND ENV
+// It returns nil if the state persistence is not enabled
+func NewStateBackendFromEnv(kubeClient kubernetes.Interface) (StateBackend, error) {
+	switch backend := os.Getenv("STATE_BACKEND"); backend {
+	case "":
+		return nil, nil
+	case "configmap":
+		namespace := getEnv("STATE_CONFIGMAP_NAMESPACE", os.Getenv("POD_NAMESPACE"))
+		if namespace == "" {
+			return nil, errors.Errorf("STATE_CONFIGMAP_NAMESPACE or POD_NAMESPACE must be provided for the configmap state backend")
+		}
+		return &ConfigMapStateBackend{
+			KubeClient: kubeClient,
+			Namespace:  namespace,
+			Name:       getEnv("STATE_CONFIGMAP_NAME", "chaos-exporter-state"),
+		}, nil
+	case "file":
+		return &FileStateBackend{
+			Path: getEnv("STATE_FILE_PATH", "chaos-exporter-state.json"),
+		}, nil
+	default:
+		return nil, errors.Errorf("unsupported state backend: %s, supported values: configmap, file", backend)
+	}
+}
+
+// ConfigMapStateBackend persists the state inside a configmap
+type ConfigMapStateBackend struct {
+	KubeClient kubernetes.Interface
+	Namespace  string
+	Name       string
+}
+
+// Load reads the state from the configmap, it returns empty state if the configmap doesn't exist
+func (b *ConfigMapStateBackend) Load() (map[string]ResultData, error) {
+	configMap, err := b.KubeClient.CoreV1().ConfigMaps(b.Namespace).Get(context.Background(), b.Name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return map[string]ResultData{}, nil
+		}
+		return nil, errors.Wrapf(err, "unable to get the state configmap")
+	}
+	return decodeState([]byte(configMap.Data[stateKey]))
+}
+
+// Save writes the state inside the configmap, it creates the configmap if it doesn't exist
+func (b *ConfigMapStateBackend) Save(verdicts map[string]ResultData) error {
+	data, err := json.Marshal(verdicts)
+	if err != nil {
+		return errors.Wrapf(err, "unable to encode the state")
+	}
+
+	configMap, err := b.KubeClient.CoreV1().ConfigMaps(b.Namespace).Get(context.Background(), b.Name, metav1.GetOptions{})
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return errors.Wrapf(err, "unable to get the state configmap")
+		}
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      b.Name,
+				Namespace: b.Namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/component": "chaos-exporter",
+				},
+			},
+			Data: map[string]string{
+				stateKey: string(data),
+			},
+		}
+		_, err = b.KubeClient.CoreV1().ConfigMaps(b.Namespace).Create(context.Background(), configMap, metav1.CreateOptions{})
+		return errors.Wrapf(err, "unable to create the state configmap")
+	}
+
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[stateKey] = string(data)
+	_, err = b.KubeClient.CoreV1().ConfigMaps(b.Namespace).Update(context.Background(), configMap, metav1.UpdateOptions{})
+	return errors.Wrapf(err, "unable to update the state configmap")
+}
+
+// FileStateBackend persists the state inside a local file, it is meant for the development setups
+type FileStateBackend struct {
+	Path string
+}
+
+// Load reads the state from the file, it returns empty state if the file doesn't exist
+func (b *FileStateBackend) Load() (map[string]ResultData, error) {
+	data, err := os.ReadFile(b.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]ResultData{}, nil
+		}
+		return nil, errors.Wrapf(err, "unable to read the state file")
+	}
+	return decodeState(data)
+}
+
+// Save writes the state inside the file, it replaces the file atomically
+func (b *FileStateBackend) Save(verdicts map[string]ResultData) error {
+	data, err := json.Marshal(verdicts)
+	if err != nil {
+		return errors.Wrapf(err, "unable to encode the state")
+	}
+	tmpFile, err := os.CreateTemp(filepath.Dir(b.Path), filepath.Base(b.Path)+".tmp")
+	if err != nil {
+		return errors.Wrapf(err, "unable to create the state file")
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return errors.Wrapf(err, "unable to write the state file")
+	}
+	if err := tmpFile.Close(); err != nil {
+		return errors.Wrapf(err, "unable to write the state file")
+	}
+	return errors.Wrapf(os.Rename(tmpFile.Name(), b.Path), "unable to replace the state file")
+}
+
+// decodeState decodes the persisted state
+func decodeState(data []byte) (map[string]ResultData, error) {
+	verdicts := map[string]ResultData{}
+	if len(data) == 0 {
+		return verdicts, nil
+	}
+	if err := json.Unmarshal(data, &verdicts); err != nil {
+		return nil, errors.Wrapf(err, "unable to decode the state")
+	}
+	return verdicts, nil
+}
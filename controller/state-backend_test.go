@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestStateBackends(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]StateBackend{
+		"configmap": &ConfigMapStateBackend{
+			KubeClient: fake.NewSimpleClientset(),
+			Namespace:  "litmus",
+			Name:       "chaos-exporter-state",
+		},
+		"file": &FileStateBackend{
+			Path: filepath.Join(t.TempDir(), "state.json"),
+		},
+	}
+	for name, backend := range tests {
+		backend := backend
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// empty state is returned before the first checkpoint
+			verdicts, err := backend.Load()
+			require.NoError(t, err)
+			require.Empty(t, verdicts)
+
+			timer := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+			for _, verdict := range []string{"Awaited", "Pass"} {
+				require.NoError(t, backend.Save(map[string]ResultData{
+					"UID": {Verdict: verdict, Timer: timer, VerdictReset: true, ProbeStatuses: []ProbeDetails{{Name: "check-url", Verdict: "Passed"}}},
+				}))
+			}
+
+			verdicts, err = backend.Load()
+			require.NoError(t, err)
+			require.Len(t, verdicts, 1)
+			require.Equal(t, "Pass", verdicts["UID"].Verdict)
+			require.True(t, verdicts["UID"].VerdictReset)
+			require.True(t, timer.Equal(verdicts["UID"].Timer))
+			// the probe statuses are not persisted
+			require.Empty(t, verdicts["UID"].ProbeStatuses)
+		})
+	}
+}
+
+func Test_checkpointState(t *testing.T) {
+	t.Parallel()
+
+	backend := &FileStateBackend{Path: filepath.Join(t.TempDir(), "state.json")}
+	state := NewStateStore()
+
+	state.SetVerdict("UID", ResultData{Verdict: "Pass"})
+	savedVersion := checkpointState(backend, state, 0)
+	require.NotZero(t, savedVersion)
+
+	// unchanged verdicts are not saved again
+	state.SetVerdict("UID", ResultData{Verdict: "Pass"})
+	require.Equal(t, savedVersion, checkpointState(backend, state, savedVersion))
+
+	// restored state skips the already exported verdicts
+	verdicts, err := backend.Load()
+	require.NoError(t, err)
+	restored := NewStateStore()
+	restored.RestoreVerdicts(verdicts)
+	result, ok := restored.Verdict("UID")
+	require.True(t, ok)
+	require.Equal(t, "Pass", result.Verdict)
+
+	state.Retain(map[string]bool{})
+	_, ok = state.Verdict("UID")
+	require.False(t, ok)
+	require.NotEqual(t, savedVersion, checkpointState(backend, state, savedVersion))
+}
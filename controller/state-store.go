@@ -0,0 +1,130 @@
+/*
+Copyright 2019 LitmusChaos Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"reflect"
+	"sync"
+)
+
+// StateStore contains the state of the exported chaosresults, keyed by the chaosresult uid
+// It is safe for concurrent use
+type StateStore struct {
+	mu sync.RWMutex
+	// verdicts contains the last exported verdict of the chaosresult
+	verdicts map[string]ResultData
+	// version is incremented whenever the verdicts are modified
+	version uint64
+	// timings contains the experiment timings derived from the chaosengine events, those are not persisted
+	timings map[string]ResultTimings
+}
+
+// NewStateStore creates an empty StateStore
+func NewStateStore() *StateStore {
+	return &StateStore{
+		verdicts: map[string]ResultData{},
+		timings:  map[string]ResultTimings{},
+	}
+}
+
+// Verdict returns the last exported verdict details of the given chaosresult uid
+func (s *StateStore) Verdict(uid string) (ResultData, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	resultData, ok := s.verdicts[uid]
+	return resultData, ok
+}
+
+// SetVerdict stores the last exported verdict details of the given chaosresult uid
+func (s *StateStore) SetVerdict(uid string, resultData ResultData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old, ok := s.verdicts[uid]; ok && reflect.DeepEqual(old, resultData) {
+		return
+	}
+	s.verdicts[uid] = resultData
+	s.version++
+}
+
+// Delete prunes the complete state of the given chaosresult uid
+func (s *StateStore) Delete(uid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.verdicts[uid]; ok {
+		delete(s.verdicts, uid)
+		s.version++
+	}
+	delete(s.timings, uid)
+}
+
+// Retain prunes the state of all the chaosresults except the given uids
+func (s *StateStore) Retain(uids map[string]bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for uid := range s.verdicts {
+		if !uids[uid] {
+			delete(s.verdicts, uid)
+			s.version++
+		}
+	}
+	for uid := range s.timings {
+		if !uids[uid] {
+			delete(s.timings, uid)
+		}
+	}
+}
+
+// Timings returns the cached experiment timings of the given chaosresult uid
+func (s *StateStore) Timings(uid string) ResultTimings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.timings[uid]
+}
+
+// SetTimings caches the experiment timings of the given chaosresult uid
+func (s *StateStore) SetTimings(uid string, timings ResultTimings) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.timings[uid] = timings
+}
+
+// Verdicts returns a copy of the verdict details of all the chaosresults
+func (s *StateStore) Verdicts() (map[string]ResultData, uint64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	verdicts := make(map[string]ResultData, len(s.verdicts))
+	for uid, resultData := range s.verdicts {
+		verdicts[uid] = resultData
+	}
+	return verdicts, s.version
+}
+
+// RestoreVerdicts restores the verdict details, which are persisted by the previous exporter instance
+func (s *StateStore) RestoreVerdicts(verdicts map[string]ResultData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for uid, resultData := range verdicts {
+		s.verdicts[uid] = resultData
+	}
+}
+
+// Len returns the number of chaosresults tracked inside the store
+func (s *StateStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.verdicts)
+}
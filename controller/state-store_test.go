@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateStore(t *testing.T) {
+	t.Parallel()
+
+	state := NewStateStore()
+	resultData := ResultData{Verdict: "Awaited"}
+
+	state.SetVerdict("UID", resultData)
+	verdict, ok := state.Verdict("UID")
+	require.True(t, ok)
+	require.Equal(t, "Awaited", verdict.Verdict)
+
+	// the unchanged verdict details don't modify the version
+	_, version := state.Verdicts()
+	state.SetVerdict("UID", resultData)
+	_, unchangedVersion := state.Verdicts()
+	require.Equal(t, version, unchangedVersion)
+
+	// the state of the chaosresults other than the retained ones is pruned
+	state.SetVerdict("UID-OLD", ResultData{Verdict: "Pass"})
+	state.SetTimings("UID-OLD", ResultTimings{StartTime: 1})
+	state.SetTimings("UID", ResultTimings{StartTime: 2})
+	state.Retain(map[string]bool{"UID": true})
+	require.Equal(t, 1, state.Len())
+	require.Equal(t, ResultTimings{}, state.Timings("UID-OLD"))
+	require.Equal(t, ResultTimings{StartTime: 2}, state.Timings("UID"))
+
+	state.Delete("UID")
+	_, ok = state.Verdict("UID")
+	require.False(t, ok)
+	require.Zero(t, state.Len())
+	require.Equal(t, ResultTimings{}, state.Timings("UID"))
+}
+
+func TestStateStoreConcurrentAccess(t *testing.T) {
+	t.Parallel()
+
+	state := NewStateStore()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			uid := fmt.Sprintf("UID-%d", i)
+			for j := 0; j < 100; j++ {
+				state.SetVerdict(uid, ResultData{Verdict: fmt.Sprintf("verdict-%d", j%3)})
+				state.SetVerdict("UID", ResultData{Verdict: fmt.Sprintf("verdict-%d", j%3)})
+				state.Verdict(uid)
+				state.Verdicts()
+			}
+			state.Delete(uid)
+		}(i)
+	}
+	wg.Wait()
+
+	require.Equal(t, 1, state.Len())
+}
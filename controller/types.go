@@ -17,9 +17,10 @@ limitations under the License.
 package controller
 
 import (
+	"fmt"
 	"time"
 
-	"github.com/prometheus/client_golang/prometheus"
+	litmuschaosv1alpha1 "github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
 	clientTypes "k8s.io/apimachinery/pkg/types"
 )
 
@@ -27,23 +28,16 @@ import (
 var (
 	EngineContext = "context"
 	WorkFlowName  = "workflow_name"
-	resultStore   = map[string][]ResultData{}
-	matchVerdict  = map[string]*ResultData{}
 )
 
-// ResultData contains attributes to store metrics parameters
-// which can be used while handling chaosresult deletion
+// ResultData contains the last exported verdict details of a chaosresult
 type ResultData struct {
-	ChaosEngineContext     string
-	WorkFlowName           string
-	AppKind                string
-	AppNs                  string
-	AppLabel               string
 	Verdict                string
 	Timer                  time.Time
 	VerdictReset           bool
 	ProbeSuccessPercentage float64
-	FaultName              string
+	// ProbeStatuses are not persisted by the state backend, to bound the size of the persisted state
+	ProbeStatuses []ProbeDetails `json:"-"`
 }
 
 // ChaosResultDetails contains chaosresult details
@@ -57,16 +51,72 @@ type ChaosResultDetails struct {
 	PassedExperiments      float64
 	FailedExperiments      float64
 	AwaitedExperiments     float64
+	StoppedExperiments     float64
 	ProbeSuccessPercentage float64
 	StartTime              float64
 	EndTime                float64
 	InjectionTime          int64
 	TotalDuration          float64
+	TimingSources          TimingSources
 	ChaosEngineName        string
+	ChaosEngineUID         clientTypes.UID
 	ChaosEngineContext     string
 	Verdict                string
 	WorkflowName           string
 	FaultName              string
+	ProbeStatuses          []ProbeDetails
+	Targets                []TargetDetails
+	ErrorCode              string
+}
+
+// ProbeDetails contains the status details of an individual probe
+type ProbeDetails struct {
+	Name    string
+	Type    string
+	Mode    string
+	Verdict string
+}
+
+// TargetDetails contains the chaos status of an individual target of the experiment
+type TargetDetails struct {
+	Name        string
+	Kind        string
+	ChaosStatus string
+}
+
+// EngineWithoutExperimentsError is returned when the chaosengine doesn't contain any experiment
+type EngineWithoutExperimentsError struct {
+	EngineName string
+	Namespace  string
+}
+
+func (e *EngineWithoutExperimentsError) Error() string {
+	return fmt.Sprintf("chaosengine %s/%s doesn't contain any experiment", e.Namespace, e.EngineName)
+}
+
+// reasons of the chaosresult errors
+const (
+	ResultErrorEngineWithoutExperiments = "engine_without_experiments"
+	ResultErrorParse                    = "parse"
+	ResultErrorEvents                   = "events"
+)
+
+// resultErrorReasons contains all the reasons of the chaosresult errors
+var resultErrorReasons = []string{ResultErrorEngineWithoutExperiments, ResultErrorParse, ResultErrorEvents}
+
+// ResultError is returned when the chaos metrics can't be derived from a specific chaosresult
+// such chaosresult is skipped, without affecting the other chaosresults
+type ResultError struct {
+	Reason string
+	Err    error
+}
+
+func (e *ResultError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ResultError) Unwrap() error {
+	return e.Err
 }
 
 // NamespacedScopeMetrics contains metrics for the chaos namespace
@@ -74,6 +124,7 @@ type NamespacedScopeMetrics struct {
 	PassedExperiments         float64
 	FailedExperiments         float64
 	AwaitedExperiments        float64
+	StoppedExperiments        float64
 	ExperimentRunCount        float64
 	ExperimentsInstalledCount float64
 }
@@ -85,212 +136,20 @@ type AWSConfig struct {
 	Service     string
 }
 
-// InitializeGaugeMetrics defines schema of all the metrics
-func (gaugeMetrics *GaugeMetrics) InitializeGaugeMetrics() *GaugeMetrics {
-	gaugeMetrics.ResultPassedExperiments = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "litmuschaos",
-		Subsystem: "",
-		Name:      "passed_experiments",
-		Help:      "Total number of passed experiments",
-	},
-		[]string{"chaosresult_namespace", "chaosresult_name", "chaosengine_name", "chaosengine_context", "fault_name"},
-	)
-
-	gaugeMetrics.ResultFailedExperiments = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "litmuschaos",
-		Subsystem: "",
-		Name:      "failed_experiments",
-		Help:      "Total number of failed experiments",
-	},
-		[]string{"chaosresult_namespace", "chaosresult_name", "chaosengine_name", "chaosengine_context", "fault_name"},
-	)
-
-	gaugeMetrics.ResultAwaitedExperiments = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "litmuschaos",
-		Subsystem: "",
-		Name:      "awaited_experiments",
-		Help:      "Total number of awaited experiments",
-	},
-		[]string{"chaosresult_namespace", "chaosresult_name", "chaosengine_name", "chaosengine_context", "workflow_name", "fault_name"},
-	)
-
-	gaugeMetrics.ResultProbeSuccessPercentage = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "litmuschaos",
-		Subsystem: "",
-		Name:      "probe_success_percentage",
-		Help:      "ProbeSuccessPercentage for the experiments",
-	},
-		[]string{"chaosresult_namespace", "chaosresult_name", "chaosengine_name", "chaosengine_context", "fault_name"},
-	)
-
-	gaugeMetrics.ResultVerdict = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "litmuschaos",
-		Subsystem: "",
-		Name:      "experiment_verdict",
-		Help:      "Verdict of the experiments",
-	},
-		[]string{"chaosresult_namespace", "chaosresult_name", "chaosengine_name", "chaosengine_context", "chaosresult_verdict",
-			"probe_success_percentage", "app_label", "app_namespace", "app_kind", "workflow_name", "fault_name"},
-	)
-
-	gaugeMetrics.ExperimentStartTime = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "litmuschaos",
-		Subsystem: "",
-		Name:      "experiment_start_time",
-		Help:      "start time of the experiments",
-	},
-		[]string{"chaosresult_namespace", "chaosresult_name", "chaosengine_name", "chaosengine_context", "fault_name"},
-	)
-
-	gaugeMetrics.ExperimentEndTime = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "litmuschaos",
-		Subsystem: "",
-		Name:      "experiment_end_time",
-		Help:      "end time of the experiments",
-	},
-		[]string{"chaosresult_namespace", "chaosresult_name", "chaosengine_name", "chaosengine_context", "fault_name"},
-	)
-
-	gaugeMetrics.ExperimentChaosInjectedTime = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "litmuschaos",
-		Subsystem: "",
-		Name:      "experiment_chaos_injected_time",
-		Help:      "chaos injected time of the experiments",
-	},
-		[]string{"chaosresult_namespace", "chaosresult_name", "chaosengine_name", "chaosengine_context", "fault_name"},
-	)
-
-	gaugeMetrics.ExperimentTotalDuration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "litmuschaos",
-		Subsystem: "",
-		Name:      "experiment_total_duration",
-		Help:      "total duration of the experiments",
-	},
-		[]string{"chaosresult_namespace", "chaosresult_name", "chaosengine_name", "chaosengine_context", "fault_name"},
-	)
-
-	gaugeMetrics.NamespaceScopedTotalPassedExperiments = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "litmuschaos",
-		Subsystem: "namespace_scoped",
-		Name:      "passed_experiments",
-		Help:      "Total number of passed experiments in watch namespace",
-	},
-		[]string{"chaosresult_namespace"},
-	)
-
-	gaugeMetrics.NamespaceScopedTotalFailedExperiments = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "litmuschaos",
-		Subsystem: "namespace_scoped",
-		Name:      "failed_experiments",
-		Help:      "Total number of failed experiments in watch namespace",
-	},
-		[]string{"chaosresult_namespace"},
-	)
-
-	gaugeMetrics.NamespaceScopedTotalAwaitedExperiments = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "litmuschaos",
-		Subsystem: "namespace_scoped",
-		Name:      "awaited_experiments",
-		Help:      "Total number of awaited experiments in watch namespace",
-	},
-		[]string{"chaosresult_namespace"},
-	)
-
-	gaugeMetrics.NamespaceScopedExperimentsRunCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "litmuschaos",
-		Subsystem: "namespace_scoped",
-		Name:      "experiments_run_count",
-		Help:      "Total experiments run in watch namespace",
-	},
-		[]string{"chaosresult_namespace"},
-	)
-
-	gaugeMetrics.NamespaceScopedExperimentsInstalledCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "litmuschaos",
-		Subsystem: "namespace_scoped",
-		Name:      "experiments_installed_count",
-		Help:      "Total number of experiments in watch namespace",
-	},
-		[]string{"chaosresult_namespace"},
-	)
-
-	gaugeMetrics.ClusterScopedTotalPassedExperiments = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "litmuschaos",
-		Subsystem: "cluster_scoped",
-		Name:      "passed_experiments",
-		Help:      "Total number of passed experiments in all namespaces",
-	},
-		[]string{},
-	)
-
-	gaugeMetrics.ClusterScopedTotalFailedExperiments = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "litmuschaos",
-		Subsystem: "cluster_scoped",
-		Name:      "failed_experiments",
-		Help:      "Total number of failed experiments in all namespaces",
-	},
-		[]string{},
-	)
-
-	gaugeMetrics.ClusterScopedTotalAwaitedExperiments = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "litmuschaos",
-		Subsystem: "cluster_scoped",
-		Name:      "awaited_experiments",
-		Help:      "Total number of awaited experiments in all namespaces",
-	},
-		[]string{},
-	)
-
-	gaugeMetrics.ClusterScopedExperimentsRunCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "litmuschaos",
-		Subsystem: "cluster_scoped",
-		Name:      "experiments_run_count",
-		Help:      "Total experiments run in all namespaces",
-	},
-		[]string{},
-	)
-
-	gaugeMetrics.ClusterScopedExperimentsInstalledCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "litmuschaos",
-		Subsystem: "cluster_scoped",
-		Name:      "experiments_installed_count",
-		Help:      "Total number of experiments in all namespaces",
-	},
-		[]string{},
-	)
-	return gaugeMetrics
-}
-
-// GaugeMetrics contains all the metrics definition
-type GaugeMetrics struct {
-	ResultPassedExperiments                  *prometheus.GaugeVec
-	ResultFailedExperiments                  *prometheus.GaugeVec
-	ResultAwaitedExperiments                 *prometheus.GaugeVec
-	ResultProbeSuccessPercentage             *prometheus.GaugeVec
-	ResultVerdict                            *prometheus.GaugeVec
-	ExperimentStartTime                      *prometheus.GaugeVec
-	ExperimentEndTime                        *prometheus.GaugeVec
-	ExperimentTotalDuration                  *prometheus.GaugeVec
-	ExperimentChaosInjectedTime              *prometheus.GaugeVec
-	NamespaceScopedTotalPassedExperiments    *prometheus.GaugeVec
-	NamespaceScopedTotalFailedExperiments    *prometheus.GaugeVec
-	NamespaceScopedTotalAwaitedExperiments   *prometheus.GaugeVec
-	NamespaceScopedExperimentsInstalledCount *prometheus.GaugeVec
-	NamespaceScopedExperimentsRunCount       *prometheus.GaugeVec
-	ClusterScopedTotalPassedExperiments      *prometheus.GaugeVec
-	ClusterScopedTotalFailedExperiments      *prometheus.GaugeVec
-	ClusterScopedTotalAwaitedExperiments     *prometheus.GaugeVec
-	ClusterScopedExperimentsInstalledCount   *prometheus.GaugeVec
-	ClusterScopedExperimentsRunCount         *prometheus.GaugeVec
-}
-
 type MetricesCollecter struct {
 	ResultCollector ResultCollector
-	GaugeMetrics    GaugeMetrics
-}
-
-// MonitoringEnabled contains existence/availability of chaosEngines and chaosResults
-type MonitoringEnabled struct {
-	IsChaosResultsAvailable bool
-	IsChaosEnginesAvailable bool
+	// Metrics exports the chaos metrics from the snapshot of the processed chaosresults
+	Metrics         *ChaosMetricsCollector
+	ExporterMetrics ExporterMetrics
+	// State contains the state of the exported chaosresults, it should be
+	// shared with the ResultCollector
+	State *StateStore
+	// Sinks publish the chaos metrics to the external backends
+	Sinks []Sink
+	// Notifiers are notified about the chaosresult verdict transitions
+	Notifiers []VerdictNotifier
+	// Aggregates maintains the aggregate metrics of the processed chaosresults
+	Aggregates *ResultAggregates
+	// results contains the last processed chaosresults, keyed by the chaosresult key
+	results map[string]*litmuschaosv1alpha1.ChaosResult
 }
@@ -0,0 +1,165 @@
+/*
+Copyright 2019 LitmusChaos Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/clients"
+	"github.com/litmuschaos/chaos-exporter/pkg/log"
+	clientv1alpha1 "github.com/litmuschaos/chaos-operator/pkg/client/clientset/versioned"
+)
+
+const (
+	// litmusAPIVersion is the api version of the chaosengine and chaosresult
+	litmusAPIVersion = "litmuschaos.io/v1alpha1"
+	// verdictChangedReason is the reason of the events emitted on the verdict transitions
+	verdictChangedReason = "VerdictChanged"
+	// exporterFieldManager is the field manager of the changes made by the exporter
+	exporterFieldManager = "chaos-exporter"
+
+	// annotations set on the chaosresult on the verdict transitions
+	verdictAnnotation                = "chaos-exporter.litmuschaos.io/verdict"
+	previousVerdictAnnotation        = "chaos-exporter.litmuschaos.io/previous-verdict"
+	probeSuccessPercentageAnnotation = "chaos-exporter.litmuschaos.io/probe-success-percentage"
+	verdictTransitionTimeAnnotation  = "chaos-exporter.litmuschaos.io/verdict-transition-time"
+)
+
+// VerdictChange contains the details of a chaosresult verdict transition
+type VerdictChange struct {
+	// ResultDetails contains the latest details of the chaosresult
+	ResultDetails ChaosResultDetails
+	// OldVerdict is the previously exported verdict
+	OldVerdict string
+	// OldProbeSuccessPercentage is the previously exported probe success percentage
+	OldProbeSuccessPercentage float64
+	// Time is the time at which the transition is observed
+	Time time.Time
+}
+
+// VerdictNotifier is notified whenever the verdict of a chaosresult changes
+type VerdictNotifier interface {
+	NotifyVerdictChange(change VerdictChange) error
+}
+
+// NewVerdictNotifiersFromEnv creates the verdict notifiers enabled by the ENABLE_VERDICT_EVENTS
+// and ENABLE_VERDICT_ANNOTATIONS ENVs
+func NewVerdictNotifiersFromEnv(clientSets clients.ClientSets) ([]VerdictNotifier, error) {
+	eventsEnabled, err := strconv.ParseBool(getEnv("ENABLE_VERDICT_EVENTS", "false"))
+	if err != nil {
+		return nil, errors.Errorf("invalid value of ENABLE_VERDICT_EVENTS ENV: %v", err)
+	}
+	annotationsEnabled, err := strconv.ParseBool(getEnv("ENABLE_VERDICT_ANNOTATIONS", "false"))
+	if err != nil {
+		return nil, errors.Errorf("invalid value of ENABLE_VERDICT_ANNOTATIONS ENV: %v", err)
+	}
+
+	var notifiers []VerdictNotifier
+	if eventsEnabled {
+		broadcaster := record.NewBroadcaster()
+		broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: clientSets.KubeClient.CoreV1().Events("")})
+		notifiers = append(notifiers, &EventNotifier{
+			Recorder: broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "chaos-exporter"}),
+		})
+	}
+	if annotationsEnabled {
+		notifiers = append(notifiers, &AnnotationNotifier{LitmusClient: clientSets.LitmusClient})
+	}
+	return notifiers, nil
+}
+
+// EventNotifier emits the kubernetes events on the chaosresult and chaosengine for the verdict transitions
+type EventNotifier struct {
+	Recorder record.EventRecorder
+}
+
+// NotifyVerdictChange emits the verdict transition events, the failed verdict is emitted as warning event
+func (notifier *EventNotifier) NotifyVerdictChange(change VerdictChange) error {
+	resultDetails := change.ResultDetails
+	eventType := corev1.EventTypeNormal
+	if resultDetails.Verdict == "Fail" {
+		eventType = corev1.EventTypeWarning
+	}
+	message := fmt.Sprintf("chaosresult %s verdict changed from %s to %s, probe success percentage changed from %v to %v",
+		resultDetails.Name, change.OldVerdict, resultDetails.Verdict, change.OldProbeSuccessPercentage, resultDetails.ProbeSuccessPercentage)
+
+	notifier.Recorder.Event(&corev1.ObjectReference{
+		APIVersion: litmusAPIVersion,
+		Kind:       "ChaosResult",
+		Name:       resultDetails.Name,
+		Namespace:  resultDetails.Namespace,
+		UID:        resultDetails.UID,
+	}, eventType, verdictChangedReason, message)
+	if resultDetails.ChaosEngineName != "" {
+		notifier.Recorder.Event(&corev1.ObjectReference{
+			APIVersion: litmusAPIVersion,
+			Kind:       "ChaosEngine",
+			Name:       resultDetails.ChaosEngineName,
+			Namespace:  resultDetails.Namespace,
+			UID:        resultDetails.ChaosEngineUID,
+		}, eventType, verdictChangedReason, message)
+	}
+	return nil
+}
+
+// AnnotationNotifier records the latest verdict transition inside the chaosresult annotations
+type AnnotationNotifier struct {
+	LitmusClient clientv1alpha1.Interface
+}
+
+// NotifyVerdictChange patches the verdict transition annotations of the chaosresult
+func (notifier *AnnotationNotifier) NotifyVerdictChange(change VerdictChange) error {
+	resultDetails := change.ResultDetails
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				verdictAnnotation:                resultDetails.Verdict,
+				previousVerdictAnnotation:        change.OldVerdict,
+				probeSuccessPercentageAnnotation: strconv.FormatFloat(resultDetails.ProbeSuccessPercentage, 'f', -1, 64),
+				verdictTransitionTimeAnnotation:  change.Time.UTC().Format(time.RFC3339),
+			},
+		},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "unable to encode the annotations patch")
+	}
+	_, err = notifier.LitmusClient.LitmuschaosV1alpha1().ChaosResults(resultDetails.Namespace).
+		Patch(context.Background(), resultDetails.Name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: exporterFieldManager})
+	return errors.Wrapf(err, "unable to annotate the chaosresult %s/%s", resultDetails.Namespace, resultDetails.Name)
+}
+
+// notifyVerdictChange notifies all the verdict notifiers about the verdict transition
+// the notifier failures are logged, they don't fail the metrics collection
+func (m *MetricesCollecter) notifyVerdictChange(change VerdictChange) {
+	for _, notifier := range m.Notifiers {
+		if err := notifier.NotifyVerdictChange(change); err != nil {
+			log.Errorf("Unable to notify the verdict change of chaosresult %s/%s, err: %v", change.ResultDetails.Namespace, change.ResultDetails.Name, err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
+	litmusFakeClientSet "github.com/litmuschaos/chaos-operator/pkg/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+// fakeNotifier records the verdict changes
+type fakeNotifier struct {
+	changes []VerdictChange
+}
+
+func (notifier *fakeNotifier) NotifyVerdictChange(change VerdictChange) error {
+	notifier.changes = append(notifier.changes, change)
+	return nil
+}
+
+// referenceRecorder records the references of the objects, on which the events are emitted
+type referenceRecorder struct {
+	*record.FakeRecorder
+	references []*corev1.ObjectReference
+}
+
+func (recorder *referenceRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	recorder.references = append(recorder.references, object.(*corev1.ObjectReference))
+	recorder.FakeRecorder.Event(object, eventtype, reason, message)
+}
+
+func TestEventNotifier(t *testing.T) {
+	tests := []struct {
+		name           string
+		change         VerdictChange
+		expectedEvents []string
+		expectedUIDs   []types.UID
+	}{
+		{
+			name: "success: passed verdict",
+			change: VerdictChange{
+				ResultDetails: ChaosResultDetails{Name: "engine-pod-delete", Namespace: "litmus", UID: "RESULT-UID", ChaosEngineName: "engine",
+					ChaosEngineUID: "ENGINE-UID", Verdict: "Pass", ProbeSuccessPercentage: 100},
+				OldVerdict: "Awaited",
+			},
+			expectedEvents: []string{
+				"Normal VerdictChanged chaosresult engine-pod-delete verdict changed from Awaited to Pass, probe success percentage changed from 0 to 100",
+				"Normal VerdictChanged chaosresult engine-pod-delete verdict changed from Awaited to Pass, probe success percentage changed from 0 to 100",
+			},
+			expectedUIDs: []types.UID{"RESULT-UID", "ENGINE-UID"},
+		},
+		{
+			name: "success: failed verdict without chaosengine",
+			change: VerdictChange{
+				ResultDetails: ChaosResultDetails{Name: "engine-pod-delete", Namespace: "litmus", UID: "RESULT-UID", Verdict: "Fail", ProbeSuccessPercentage: 50},
+				OldVerdict:    "Awaited",
+			},
+			expectedEvents: []string{
+				"Warning VerdictChanged chaosresult engine-pod-delete verdict changed from Awaited to Fail, probe success percentage changed from 0 to 50",
+			},
+			expectedUIDs: []types.UID{"RESULT-UID"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &referenceRecorder{FakeRecorder: record.NewFakeRecorder(10)}
+			notifier := &EventNotifier{Recorder: recorder}
+			require.NoError(t, notifier.NotifyVerdictChange(tt.change))
+			close(recorder.Events)
+
+			var events []string
+			for event := range recorder.Events {
+				events = append(events, event)
+			}
+			require.Equal(t, tt.expectedEvents, events)
+			// the events are emitted on the objects having the uid, so that those are listed by kubectl describe
+			var uids []types.UID
+			for _, reference := range recorder.references {
+				uids = append(uids, reference.UID)
+			}
+			require.Equal(t, tt.expectedUIDs, uids)
+		})
+	}
+}
+
+func TestAnnotationNotifier(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *v1alpha1.ChaosResult
+		isErr  bool
+	}{
+		{
+			name: "success: chaosresult annotated",
+			result: &v1alpha1.ChaosResult{
+				ObjectMeta: metav1.ObjectMeta{Name: "engine-pod-delete", Namespace: "litmus"},
+			},
+		},
+		{
+			name: "failure: chaosresult not found",
+			result: &v1alpha1.ChaosResult{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "litmus"},
+			},
+			isErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := litmusFakeClientSet.NewSimpleClientset(tt.result)
+			notifier := &AnnotationNotifier{LitmusClient: client}
+			transitionTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+			err := notifier.NotifyVerdictChange(VerdictChange{
+				ResultDetails: ChaosResultDetails{Name: "engine-pod-delete", Namespace: "litmus", Verdict: "Pass", ProbeSuccessPercentage: 100},
+				OldVerdict:    "Awaited",
+				Time:          transitionTime,
+			})
+			if tt.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			result, err := client.LitmuschaosV1alpha1().ChaosResults("litmus").Get(context.Background(), "engine-pod-delete", metav1.GetOptions{})
+			require.NoError(t, err)
+			require.Equal(t, map[string]string{
+				verdictAnnotation:                "Pass",
+				previousVerdictAnnotation:        "Awaited",
+				probeSuccessPercentageAnnotation: "100",
+				verdictTransitionTimeAnnotation:  "2024-01-02T03:04:05Z",
+			}, result.Annotations)
+		})
+	}
+}
@@ -6,10 +6,14 @@ import (
 	"os"
 	"time"
 
+	"github.com/litmuschaos/chaos-exporter/pkg/log"
+	litmuschaosv1alpha1 "github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
 	clientv1alpha1 "github.com/litmuschaos/chaos-operator/pkg/client/clientset/versioned"
 	litmusInformer "github.com/litmuschaos/chaos-operator/pkg/client/informers/externalversions"
 	"github.com/litmuschaos/chaos-operator/pkg/client/listers/litmuschaos/v1alpha1"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	v1 "k8s.io/client-go/listers/core/v1"
@@ -23,16 +27,40 @@ import (
 type ClientSets struct {
 	KubeClient     kubernetes.Interface
 	EventsInformer v1.EventLister
+	// EventsIndexer indexes the events by the uid of their involved object
+	EventsIndexer  cache.Indexer
 	EngineInformer v1alpha1.ChaosEngineLister
 	ResultInformer v1alpha1.ChaosResultLister
 	LitmusClient   clientv1alpha1.Interface
 	KubeConfig     *rest.Config
+	// InformersSynced reports whether all the informer caches are synced
+	InformersSynced cache.InformerSynced
 }
 
 const (
-	ProcessKey = "process"
+	// EngineNameIndex is the name of the chaosresults index keyed by the namespaced name of their chaosengine
+	EngineNameIndex = "engineName"
+	// InvolvedObjectUIDIndex is the name of the events index keyed by the uid of the involved object
+	InvolvedObjectUIDIndex = "involvedObjectUID"
 )
 
+var kubeconfig string
+
+// InformerEvents counts the informer events received per resource and event type
+var InformerEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "chaos_exporter",
+	Subsystem: "informer",
+	Name:      "events_total",
+	Help:      "Total number of informer events received per resource and event type",
+},
+	[]string{"resource", "event"},
+)
+
+// RegisterFlags registers the kubeconfig flag, it should be called before the flags are parsed
+func RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&kubeconfig, "kubeconfig", "", "absolute path to the kubeconfig file")
+}
+
 // NewClientSet will generation both ClientSets (k8s, and Litmus) as well as the KubeConfig
 func NewClientSet(stopCh <-chan struct{}, resyncDuration time.Duration, wq workqueue.RateLimitingInterface) (ClientSets, error) {
 
@@ -77,34 +105,27 @@ func (clientSets *ClientSets) SetupInformers(stopCh <-chan struct{}, k8sClientSe
 	}
 
 	eventsInformer := factory.Core().V1().Events().Informer()
+	// index the events by the involved object uid, so that the events of a chaosengine
+	// are looked up without listing all the events of the namespace
+	if err := eventsInformer.AddIndexers(cache.Indexers{InvolvedObjectUIDIndex: InvolvedObjectUIDIndexFunc}); err != nil {
+		return errors.Wrapf(err, "unable to add the events indexer")
+	}
 	clientSets.EventsInformer = factory.Core().V1().Events().Lister()
+	clientSets.EventsIndexer = eventsInformer.GetIndexer()
 
 	chaosEngineInformer := litmusFactory.Litmuschaos().V1alpha1().ChaosEngines().Informer()
 	chaosResultInformer := litmusFactory.Litmuschaos().V1alpha1().ChaosResults().Informer()
 
-	// queue up for processing if there is any change in the resources
-	chaosEngineInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc: func(obj interface{}) {
-			wq.Add(ProcessKey)
-		},
-		UpdateFunc: func(old, new interface{}) {
-			wq.Add(ProcessKey)
-		},
-		DeleteFunc: func(obj interface{}) {
-			wq.Add(ProcessKey)
-		},
-	})
-	chaosResultInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc: func(obj interface{}) {
-			wq.Add(ProcessKey)
-		},
-		UpdateFunc: func(old, new interface{}) {
-			wq.Add(ProcessKey)
-		},
-		DeleteFunc: func(obj interface{}) {
-			wq.Add(ProcessKey)
-		},
-	})
+	// index the chaosresults by their chaosengine, so that the chaosresults
+	// of the updated chaosengine are queued up without listing all the chaosresults
+	if err := chaosResultInformer.AddIndexers(cache.Indexers{EngineNameIndex: EngineNameIndexFunc}); err != nil {
+		return errors.Wrapf(err, "unable to add the chaosresults indexer")
+	}
+
+	// queue up the changed chaosresult for processing, the chaosresults of the
+	// chaosengine are queued up if there is any change in the chaosengine
+	chaosEngineInformer.AddEventHandler(enqueueHandler("chaosengine", wq, engineResultKeys(chaosResultInformer.GetIndexer())))
+	chaosResultInformer.AddEventHandler(enqueueHandler("chaosresult", wq, resultKeys))
 
 	clientSets.EngineInformer = litmusFactory.Litmuschaos().V1alpha1().ChaosEngines().Lister()
 	clientSets.ResultInformer = litmusFactory.Litmuschaos().V1alpha1().ChaosResults().Lister()
@@ -113,18 +134,101 @@ func (clientSets *ClientSets) SetupInformers(stopCh <-chan struct{}, k8sClientSe
 	go chaosEngineInformer.Run(stopCh)
 	go chaosResultInformer.Run(stopCh)
 
+	clientSets.InformersSynced = func() bool {
+		return eventsInformer.HasSynced() && chaosEngineInformer.HasSynced() && chaosResultInformer.HasSynced()
+	}
 	if !cache.WaitForCacheSync(stopCh, eventsInformer.HasSynced, chaosEngineInformer.HasSynced, chaosResultInformer.HasSynced) {
 		return fmt.Errorf("timed out waiting for caches to sync")
 	}
 	return nil
 }
 
+// InvolvedObjectUIDIndexFunc indexes the events by the uid of their involved object
+func InvolvedObjectUIDIndexFunc(obj interface{}) ([]string, error) {
+	event, ok := obj.(*corev1.Event)
+	if !ok {
+		return nil, fmt.Errorf("expected event, got %T", obj)
+	}
+	if event.InvolvedObject.UID == "" {
+		return nil, nil
+	}
+	return []string{string(event.InvolvedObject.UID)}, nil
+}
+
+// EngineNameIndexFunc indexes the chaosresults by the namespaced name of their chaosengine
+func EngineNameIndexFunc(obj interface{}) ([]string, error) {
+	chaosResult, ok := obj.(*litmuschaosv1alpha1.ChaosResult)
+	if !ok {
+		return nil, fmt.Errorf("expected chaosresult, got %T", obj)
+	}
+	if chaosResult.Spec.EngineName == "" {
+		return nil, nil
+	}
+	return []string{chaosResult.Namespace + "/" + chaosResult.Spec.EngineName}, nil
+}
+
+// keysFunc derive the workqueue keys of the chaosresults, which should be processed for the given object
+type keysFunc func(obj interface{}) []string
+
+// resultKeys returns the key of the given chaosresult
+func resultKeys(obj interface{}) []string {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		log.Errorf("Unable to derive the key of chaosresult, err: %v", err)
+		return nil
+	}
+	return []string{key}
+}
+
+// engineResultKeys returns the keys of the chaosresults belonging to the given chaosengine
+func engineResultKeys(resultIndexer cache.Indexer) keysFunc {
+	return func(obj interface{}) []string {
+		engineKey, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+		if err != nil {
+			log.Errorf("Unable to derive the key of chaosengine, err: %v", err)
+			return nil
+		}
+		chaosResults, err := resultIndexer.ByIndex(EngineNameIndex, engineKey)
+		if err != nil {
+			log.Errorf("Unable to list the chaosresults of chaosengine %s, err: %v", engineKey, err)
+			return nil
+		}
+		var keys []string
+		for _, chaosResult := range chaosResults {
+			keys = append(keys, resultKeys(chaosResult)...)
+		}
+		return keys
+	}
+}
+
+// enqueueHandler queue up the keys of the chaosresults affected by every event of the given resource
+// and counts the received events
+func enqueueHandler(resource string, wq workqueue.RateLimitingInterface, keys keysFunc) cache.ResourceEventHandlerFuncs {
+	enqueue := func(obj interface{}) {
+		for _, key := range keys(obj) {
+			wq.Add(key)
+		}
+	}
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			InformerEvents.WithLabelValues(resource, "add").Inc()
+			enqueue(obj)
+		},
+		UpdateFunc: func(old, new interface{}) {
+			InformerEvents.WithLabelValues(resource, "update").Inc()
+			enqueue(new)
+		},
+		DeleteFunc: func(obj interface{}) {
+			InformerEvents.WithLabelValues(resource, "delete").Inc()
+			enqueue(obj)
+		},
+	}
+}
+
 // getKubeConfig setup the config for access cluster resource
 func getKubeConfig() (*rest.Config, error) {
-	kubeconfig := flag.String("kubeconfig", "", "absolute path to the kubeconfig file")
-	flag.Parse()
 	// It uses in-cluster config, if kubeconfig path is not specified
-	config, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
 	return config, err
 }
 
@@ -0,0 +1,12 @@
+package env
+
+import "os"
+
+// Get returns the value of the given ENV, the default value is returned if the ENV is empty
+func Get(key, defaultValue string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		value = defaultValue
+	}
+	return value
+}
@@ -0,0 +1,127 @@
+package health
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Config contains the configuration of the health checks
+type Config struct {
+	LivenessThreshold time.Duration
+}
+
+// RegisterFlags registers the health check flags, the ENVs are used as the default values
+func (config *Config) RegisterFlags(fs *flag.FlagSet) {
+	threshold, err := time.ParseDuration(os.Getenv("LIVENESS_THRESHOLD"))
+	if err != nil || threshold <= 0 {
+		threshold = 10 * time.Minute
+	}
+	fs.DurationVar(&config.LivenessThreshold, "liveness-threshold", threshold, "maximum duration since the last successful metrics collection before the exporter is reported as not alive")
+}
+
+// Checker tracks the liveness and readiness of the exporter
+type Checker struct {
+	livenessThreshold time.Duration
+
+	mu              sync.RWMutex
+	lastSuccess     time.Time
+	informersSynced func() bool
+	idle            func() bool
+	standby         bool
+}
+
+// NewChecker creates the health checker, the liveness threshold is counted
+// from the creation time until the first successful metrics collection
+func NewChecker(config Config) *Checker {
+	return &Checker{
+		livenessThreshold: config.LivenessThreshold,
+		lastSuccess:       time.Now(),
+	}
+}
+
+// SetInformersSynced sets the function reporting the sync state of the informers
+func (c *Checker) SetInformersSynced(informersSynced func() bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.informersSynced = informersSynced
+}
+
+// SetIdle sets the function reporting whether the exporter has no pending work, i.e, it is waiting
+// for the changes of the chaosresults, e.g, there are no chaosresults to collect the metrics from
+func (c *Checker) SetIdle(idle func() bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.idle = idle
+}
+
+// SetStandby marks the exporter as standby replica, which doesn't collect the metrics
+// while it is waiting for the leadership
+func (c *Checker) SetStandby(standby bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.standby = standby
+	c.lastSuccess = time.Now()
+}
+
+// RecordSuccess records the successful metrics collection
+func (c *Checker) RecordSuccess() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastSuccess = time.Now()
+}
+
+// Ready checks whether the informer caches are synced
+func (c *Checker) Ready() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.informersSynced == nil || !c.informersSynced() {
+		return fmt.Errorf("informer caches are not synced")
+	}
+	return nil
+}
+
+// Alive checks whether the metrics are collected successfully within the liveness threshold
+// the standby replica is always alive, as it doesn't collect the metrics, and so is the idle exporter
+// having the synced informer caches, as there is nothing to collect until the chaosresults change
+func (c *Checker) Alive() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.standby {
+		return nil
+	}
+	if c.idle != nil && c.idle() && c.informersSynced != nil && c.informersSynced() {
+		return nil
+	}
+	if since := time.Since(c.lastSuccess); since > c.livenessThreshold {
+		return fmt.Errorf("last successful metrics collection was %v ago, threshold: %v", since.Round(time.Second), c.livenessThreshold)
+	}
+	return nil
+}
+
+// LivenessHandler serves the liveness check
+func (c *Checker) LivenessHandler() http.Handler {
+	return checkHandler(c.Alive)
+}
+
+// ReadinessHandler serves the readiness check
+func (c *Checker) ReadinessHandler() http.Handler {
+	return checkHandler(c.Ready)
+}
+
+// checkHandler serves the result of the given check
+func checkHandler(check func() error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if err := check(); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintln(w, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "ok")
+	})
+}
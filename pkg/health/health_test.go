@@ -0,0 +1,113 @@
+package health
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadinessHandler(t *testing.T) {
+	tests := map[string]struct {
+		informersSynced func() bool
+		expectedStatus  int
+	}{
+		"success: informers synced": {
+			informersSynced: func() bool { return true },
+			expectedStatus:  http.StatusOK,
+		},
+		"failure: informers not synced": {
+			informersSynced: func() bool { return false },
+			expectedStatus:  http.StatusServiceUnavailable,
+		},
+		"failure: informers not started": {
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			checker := NewChecker(Config{LivenessThreshold: time.Minute})
+			if tt.informersSynced != nil {
+				checker.SetInformersSynced(tt.informersSynced)
+			}
+			rec := httptest.NewRecorder()
+			checker.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			require.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestLivenessHandler(t *testing.T) {
+	tests := map[string]struct {
+		lastSuccess    time.Duration
+		standby        bool
+		expectedStatus int
+	}{
+		"success: collected within threshold": {
+			lastSuccess:    10 * time.Second,
+			expectedStatus: http.StatusOK,
+		},
+		"failure: collection stalled": {
+			lastSuccess:    2 * time.Minute,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		"success: standby replica doesn't collect": {
+			lastSuccess:    2 * time.Minute,
+			standby:        true,
+			expectedStatus: http.StatusOK,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			checker := NewChecker(Config{LivenessThreshold: time.Minute})
+			checker.SetStandby(tt.standby)
+			checker.lastSuccess = time.Now().Add(-tt.lastSuccess)
+			rec := httptest.NewRecorder()
+			checker.LivenessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			require.Equal(t, tt.expectedStatus, rec.Code)
+
+			checker.RecordSuccess()
+			rec = httptest.NewRecorder()
+			checker.LivenessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			require.Equal(t, http.StatusOK, rec.Code)
+		})
+	}
+}
+
+func TestLivenessHandlerIdle(t *testing.T) {
+	tests := map[string]struct {
+		idle            bool
+		informersSynced bool
+		expectedStatus  int
+	}{
+		"success: idle exporter without any chaosresult": {
+			idle:            true,
+			informersSynced: true,
+			expectedStatus:  http.StatusOK,
+		},
+		"failure: pending chaosresults not collected": {
+			idle:            false,
+			informersSynced: true,
+			expectedStatus:  http.StatusServiceUnavailable,
+		},
+		"failure: informers not synced": {
+			idle:            true,
+			informersSynced: false,
+			expectedStatus:  http.StatusServiceUnavailable,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			checker := NewChecker(Config{LivenessThreshold: time.Minute})
+			checker.SetInformersSynced(func() bool { return tt.informersSynced })
+			checker.SetIdle(func() bool { return tt.idle })
+			// no metrics are collected since the liveness threshold
+			checker.lastSuccess = time.Now().Add(-2 * time.Minute)
+			rec := httptest.NewRecorder()
+			checker.LivenessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			require.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package leader
+
+import (
+	"context"
+	"flag"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/env"
+	"github.com/litmuschaos/chaos-exporter/pkg/log"
+)
+
+// IsLeader reports whether the exporter replica is the leader, the followers are in standby
+var IsLeader = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "chaos_exporter",
+	Subsystem: "",
+	Name:      "leader",
+	Help:      "Leadership status of the exporter replica, 1 for the leader and 0 for the standby replica",
+},
+	[]string{"identity"},
+)
+
+// Config contains the leader election configuration
+type Config struct {
+	Enabled        bool
+	LeaseName      string
+	LeaseNamespace string
+	Identity       string
+	LeaseDuration  time.Duration
+	RenewDeadline  time.Duration
+	RetryPeriod    time.Duration
+}
+
+// RegisterFlags registers the leader election flags, the ENVs are used as the default values
+func (config *Config) RegisterFlags(fs *flag.FlagSet) {
+	enabled, _ := strconv.ParseBool(os.Getenv("LEADER_ELECT"))
+	identity := os.Getenv("POD_NAME")
+	if identity == "" {
+		identity, _ = os.Hostname()
+	}
+	fs.BoolVar(&config.Enabled, "leader-elect", enabled, "enable the leader election, only the leader collects and pushes the chaos metrics")
+	fs.StringVar(&config.LeaseName, "leader-election-lease-name", env.Get("LEADER_ELECTION_LEASE_NAME", "chaos-exporter-leader"), "name of the lease used for the leader election")
+	fs.StringVar(&config.LeaseNamespace, "leader-election-namespace", env.Get("LEADER_ELECTION_NAMESPACE", os.Getenv("POD_NAMESPACE")), "namespace of the lease used for the leader election")
+	fs.StringVar(&config.Identity, "leader-election-identity", identity, "identity of the exporter replica inside the lease")
+	fs.DurationVar(&config.LeaseDuration, "leader-election-lease-duration", 15*time.Second, "duration for which the followers wait before acquiring the leadership")
+	fs.DurationVar(&config.RenewDeadline, "leader-election-renew-deadline", 10*time.Second, "duration for which the leader retries renewing the leadership")
+	fs.DurationVar(&config.RetryPeriod, "leader-election-retry-period", 2*time.Second, "duration between the leader election retries")
+}
+
+// Run blocks until the leadership is acquired and then runs the given function
+// It exits the process once the leadership is lost, so that the replica restarts in standby,
+// unless the given context is cancelled
+func Run(ctx context.Context, config Config, kubeClient kubernetes.Interface, onStartedLeading func(ctx context.Context)) error {
+	if config.LeaseNamespace == "" {
+		return errors.Errorf("leader election namespace must be provided, either set POD_NAMESPACE or LEADER_ELECTION_NAMESPACE")
+	}
+	if config.Identity == "" {
+		return errors.Errorf("leader election identity must be provided, either set POD_NAME or leader-election-identity")
+	}
+
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      config.LeaseName,
+			Namespace: config.LeaseNamespace,
+		},
+		Client: kubeClient.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: config.Identity,
+		},
+	}
+
+	IsLeader.WithLabelValues(config.Identity).Set(0)
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   config.LeaseDuration,
+		RenewDeadline:   config.RenewDeadline,
+		RetryPeriod:     config.RetryPeriod,
+		ReleaseOnCancel: true,
+		Name:            config.LeaseName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				log.Infof("[Leader]: %s acquired the leadership", config.Identity)
+				IsLeader.WithLabelValues(config.Identity).Set(1)
+				onStartedLeading(ctx)
+			},
+			OnStoppedLeading: func() {
+				IsLeader.WithLabelValues(config.Identity).Set(0)
+				if ctx.Err() != nil {
+					log.Infof("[Leader]: %s released the leadership", config.Identity)
+					return
+				}
+				log.Fatalf("[Leader]: %s lost the leadership, restarting in standby", config.Identity)
+			},
+			OnNewLeader: func(identity string) {
+				if identity != config.Identity {
+					log.Infof("[Standby]: %s is the current leader", identity)
+				}
+			},
+		},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "unable to create the leader elector")
+	}
+	elector.Run(ctx)
+	return nil
+}
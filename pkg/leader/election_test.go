@@ -0,0 +1,70 @@
+package leader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRun(t *testing.T) {
+	tests := map[string]struct {
+		config Config
+		isErr  bool
+	}{
+		"success: leadership acquired": {
+			config: Config{
+				LeaseName:      "chaos-exporter-leader",
+				LeaseNamespace: "litmus",
+				Identity:       "exporter-0",
+			},
+		},
+		"failure: namespace not provided": {
+			config: Config{
+				LeaseName: "chaos-exporter-leader",
+				Identity:  "exporter-0",
+			},
+			isErr: true,
+		},
+		"failure: identity not provided": {
+			config: Config{
+				LeaseName:      "chaos-exporter-leader",
+				LeaseNamespace: "litmus",
+			},
+			isErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tt.config.LeaseDuration = 2 * time.Second
+			tt.config.RenewDeadline = time.Second
+			tt.config.RetryPeriod = 100 * time.Millisecond
+			client := fake.NewSimpleClientset()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			started := false
+			err := Run(ctx, tt.config, client, func(ctx context.Context) {
+				started = true
+				require.Equal(t, float64(1), testutil.ToFloat64(IsLeader.WithLabelValues(tt.config.Identity)))
+				cancel()
+			})
+			if tt.isErr {
+				require.Error(t, err)
+				require.False(t, started)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, started)
+			require.Equal(t, float64(0), testutil.ToFloat64(IsLeader.WithLabelValues(tt.config.Identity)))
+
+			lease, err := client.CoordinationV1().Leases(tt.config.LeaseNamespace).Get(context.Background(), tt.config.LeaseName, metav1.GetOptions{})
+			require.NoError(t, err)
+			require.NotNil(t, lease.Spec.HolderIdentity)
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/env"
+	"github.com/litmuschaos/chaos-exporter/pkg/log"
+)
+
+const (
+	// ProtocolGRPC exports the metrics with OTLP over gRPC
+	ProtocolGRPC = "grpc"
+	// ProtocolHTTP exports the metrics with OTLP over HTTP, encoded as protobuf
+	ProtocolHTTP = "http"
+
+	// metricPrefix is the prefix of the chaos metrics, which are mirrored
+	metricPrefix = "litmuschaos_"
+	// scopeName is the name of the instrumentation scope of the exported metrics
+	scopeName = "github.com/litmuschaos/chaos-exporter"
+)
+
+// Config contains the configuration of the OTLP exporter
+type Config struct {
+	Endpoint    string
+	Protocol    string
+	Interval    time.Duration
+	Insecure    bool
+	ClusterName string
+}
+
+// RegisterFlags registers the OTLP flags, the ENVs are used as the default values
+func (config *Config) RegisterFlags(fs *flag.FlagSet) {
+	interval, err := time.ParseDuration(os.Getenv("OTLP_INTERVAL"))
+	if err != nil || interval <= 0 {
+		interval = 30 * time.Second
+	}
+	insecure, _ := strconv.ParseBool(os.Getenv("OTLP_INSECURE"))
+	fs.StringVar(&config.Endpoint, "otlp-endpoint", os.Getenv("OTLP_ENDPOINT"), "endpoint of the OTLP collector, host:port for grpc and url for http, the OTLP export is disabled if empty")
+	fs.StringVar(&config.Protocol, "otlp-protocol", env.Get("OTLP_PROTOCOL", ProtocolGRPC), "protocol of the OTLP export, supported values: grpc, http")
+	fs.DurationVar(&config.Interval, "otlp-interval", interval, "interval between the OTLP exports")
+	fs.BoolVar(&config.Insecure, "otlp-insecure", insecure, "disable the TLS of the grpc connection to the OTLP collector")
+	fs.StringVar(&config.ClusterName, "otlp-cluster-name", os.Getenv("CLUSTER_NAME"), "name of the cluster, exported as the k8s.cluster.name resource attribute")
+}
+
+// Enabled checks whether the OTLP export is enabled
+func (config Config) Enabled() bool {
+	return config.Endpoint != ""
+}
+
+// client sends the metrics to the OTLP collector
+type client interface {
+	export(ctx context.Context, request *colmetricspb.ExportMetricsServiceRequest) error
+	close() error
+}
+
+// Exporter periodically pushes the chaos metrics gathered from the prometheus registry to the OTLP collector
+type Exporter struct {
+	config   Config
+	gatherer prometheus.Gatherer
+	client   client
+}
+
+// NewExporter creates the OTLP exporter, which mirrors the chaos metrics of the given gatherer
+func NewExporter(config Config, gatherer prometheus.Gatherer) (*Exporter, error) {
+	var (
+		c   client
+		err error
+	)
+	switch config.Protocol {
+	case ProtocolGRPC:
+		c, err = newGRPCClient(config)
+	case ProtocolHTTP:
+		c, err = newHTTPClient(config)
+	default:
+		return nil, errors.Errorf("unsupported OTLP protocol: %s, supported values: grpc, http", config.Protocol)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Exporter{
+		config:   config,
+		gatherer: gatherer,
+		client:   c,
+	}, nil
+}
+
+// Run exports the metrics every interval until the context is cancelled
+func (e *Exporter) Run(ctx context.Context) {
+	defer e.client.close()
+	ticker := time.NewTicker(e.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.Export(ctx); err != nil {
+				log.Errorf("Unable to export the OTLP metrics, err: %v", err)
+			}
+		}
+	}
+}
+
+// Export gathers and pushes the chaos metrics once
+func (e *Exporter) Export(ctx context.Context) error {
+	families, err := e.gatherer.Gather()
+	if err != nil {
+		return errors.Wrapf(err, "unable to gather the metrics")
+	}
+	ctx, cancel := context.WithTimeout(ctx, e.config.Interval)
+	defer cancel()
+	return e.client.export(ctx, e.newRequest(families, time.Now()))
+}
+
+// newRequest converts the chaos gauges to the OTLP gauges
+func (e *Exporter) newRequest(families []*dto.MetricFamily, now time.Time) *colmetricspb.ExportMetricsServiceRequest {
+	timestamp := uint64(now.UnixNano())
+	var metrics []*metricspb.Metric
+	for _, family := range families {
+		if family.GetType() != dto.MetricType_GAUGE || !strings.HasPrefix(family.GetName(), metricPrefix) {
+			continue
+		}
+		gauge := &metricspb.Gauge{}
+		for _, metric := range family.GetMetric() {
+			var attributes []*commonpb.KeyValue
+			for _, label := range metric.GetLabel() {
+				attributes = append(attributes, stringAttribute(label.GetName(), label.GetValue()))
+			}
+			gauge.DataPoints = append(gauge.DataPoints, &metricspb.NumberDataPoint{
+				Attributes:   attributes,
+				TimeUnixNano: timestamp,
+				Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: metric.GetGauge().GetValue()},
+			})
+		}
+		metrics = append(metrics, &metricspb.Metric{
+			Name:        family.GetName(),
+			Description: family.GetHelp(),
+			Data:        &metricspb.Metric_Gauge{Gauge: gauge},
+		})
+	}
+
+	resourceAttributes := []*commonpb.KeyValue{stringAttribute("service.name", "chaos-exporter")}
+	if e.config.ClusterName != "" {
+		resourceAttributes = append(resourceAttributes, stringAttribute("k8s.cluster.name", e.config.ClusterName))
+	}
+	return &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{
+			{
+				Resource: &resourcepb.Resource{Attributes: resourceAttributes},
+				ScopeMetrics: []*metricspb.ScopeMetrics{
+					{
+						Scope:   &commonpb.InstrumentationScope{Name: scopeName},
+						Metrics: metrics,
+					},
+				},
+			},
+		},
+	}
+}
+
+// stringAttribute creates the attribute with the given string value
+func stringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+// grpcClient sends the metrics with OTLP over gRPC
+type grpcClient struct {
+	conn    *grpc.ClientConn
+	service colmetricspb.MetricsServiceClient
+}
+
+// newGRPCClient creates the gRPC client, the connection is established lazily
+func newGRPCClient(config Config) (*grpcClient, error) {
+	transportCredentials := credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	if config.Insecure {
+		transportCredentials = insecure.NewCredentials()
+	}
+	conn, err := grpc.Dial(config.Endpoint, grpc.WithTransportCredentials(transportCredentials))
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to create the OTLP grpc connection")
+	}
+	return &grpcClient{
+		conn:    conn,
+		service: colmetricspb.NewMetricsServiceClient(conn),
+	}, nil
+}
+
+func (c *grpcClient) export(ctx context.Context, request *colmetricspb.ExportMetricsServiceRequest) error {
+	response, err := c.service.Export(ctx, request)
+	if err != nil {
+		return errors.Wrapf(err, "unable to export the OTLP metrics")
+	}
+	return partialSuccessError(response.GetPartialSuccess())
+}
+
+func (c *grpcClient) close() error {
+	return c.conn.Close()
+}
+
+// httpClient sends the metrics with OTLP over HTTP
+type httpClient struct {
+	url    string
+	client *http.Client
+}
+
+// newHTTPClient creates the HTTP client, the /v1/metrics path is used if the endpoint doesn't contain any path
+func newHTTPClient(config Config) (*httpClient, error) {
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, errors.Errorf("invalid OTLP http endpoint: %s, it must be an absolute url", config.Endpoint)
+	}
+	if endpoint.Path == "" || endpoint.Path == "/" {
+		endpoint.Path = "/v1/metrics"
+	}
+	return &httpClient{
+		url:    endpoint.String(),
+		client: &http.Client{},
+	}, nil
+}
+
+func (c *httpClient) export(ctx context.Context, request *colmetricspb.ExportMetricsServiceRequest) error {
+	body, err := proto.Marshal(request)
+	if err != nil {
+		return errors.Wrapf(err, "unable to encode the OTLP metrics")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrapf(err, "unable to create the OTLP request")
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "unable to export the OTLP metrics")
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read the OTLP response")
+	}
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("unable to export the OTLP metrics, status: %s, body: %s", resp.Status, string(data))
+	}
+	response := &colmetricspb.ExportMetricsServiceResponse{}
+	if err := proto.Unmarshal(data, response); err != nil {
+		return errors.Wrapf(err, "unable to decode the OTLP response")
+	}
+	return partialSuccessError(response.GetPartialSuccess())
+}
+
+func (c *httpClient) close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
+
+// partialSuccessError returns the error if the collector rejected some of the data points
+func partialSuccessError(partialSuccess *colmetricspb.ExportMetricsPartialSuccess) error {
+	if partialSuccess.GetRejectedDataPoints() == 0 {
+		return nil
+	}
+	return fmt.Errorf("OTLP collector rejected %d data points: %s", partialSuccess.GetRejectedDataPoints(), partialSuccess.GetErrorMessage())
+}
@@ -0,0 +1,166 @@
+package otlp
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// receiver is an in-process OTLP receiver, which records the received requests
+type receiver struct {
+	colmetricspb.UnimplementedMetricsServiceServer
+
+	mu       sync.Mutex
+	requests []*colmetricspb.ExportMetricsServiceRequest
+}
+
+func (r *receiver) Export(_ context.Context, request *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, request)
+	return &colmetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+// ServeHTTP serves the OTLP/HTTP protobuf requests
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != "/v1/metrics" || req.Header.Get("Content-Type") != "application/x-protobuf" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	body, _ := io.ReadAll(req.Body)
+	request := &colmetricspb.ExportMetricsServiceRequest{}
+	if err := proto.Unmarshal(body, request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	response, _ := r.Export(req.Context(), request)
+	data, _ := proto.Marshal(response)
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Write(data)
+}
+
+// startGRPCReceiver starts the OTLP/gRPC receiver and returns its address
+func startGRPCReceiver(t *testing.T, r *receiver) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	colmetricspb.RegisterMetricsServiceServer(server, r)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+// newTestRegistry creates the registry containing a chaos gauge and a non chaos metric
+func newTestRegistry(t *testing.T) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	verdict := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "litmuschaos",
+		Name:      "experiment_verdict",
+		Help:      "Verdict of the experiment",
+	}, []string{"chaosresult_name"})
+	verdict.WithLabelValues("chaosresult-1").Set(1)
+	other := prometheus.NewCounter(prometheus.CounterOpts{Name: "other_total", Help: "other"})
+	require.NoError(t, registry.Register(verdict))
+	require.NoError(t, registry.Register(other))
+	return registry
+}
+
+func TestExport(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol string
+		isErr    bool
+	}{
+		{
+			name:     "success: export over grpc",
+			protocol: ProtocolGRPC,
+		},
+		{
+			name:     "success: export over http",
+			protocol: ProtocolHTTP,
+		},
+		{
+			name:     "failure: unsupported protocol",
+			protocol: "udp",
+			isErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &receiver{}
+			config := Config{
+				Protocol:    tt.protocol,
+				Interval:    time.Second,
+				Insecure:    true,
+				ClusterName: "cluster-1",
+			}
+			switch tt.protocol {
+			case ProtocolGRPC:
+				config.Endpoint = startGRPCReceiver(t, r)
+			case ProtocolHTTP:
+				server := httptest.NewServer(r)
+				t.Cleanup(server.Close)
+				config.Endpoint = server.URL
+			}
+
+			exporter, err := NewExporter(config, newTestRegistry(t))
+			if tt.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer exporter.client.close()
+			require.NoError(t, exporter.Export(context.Background()))
+
+			require.Len(t, r.requests, 1)
+			resourceMetrics := r.requests[0].GetResourceMetrics()[0]
+			require.Equal(t, "cluster-1", resourceMetrics.GetResource().GetAttributes()[1].GetValue().GetStringValue())
+			metrics := resourceMetrics.GetScopeMetrics()[0].GetMetrics()
+			// only the chaos gauges are mirrored
+			require.Len(t, metrics, 1)
+			require.Equal(t, "litmuschaos_experiment_verdict", metrics[0].GetName())
+			dataPoints := metrics[0].Data.(*metricspb.Metric_Gauge).Gauge.GetDataPoints()
+			require.Len(t, dataPoints, 1)
+			require.Equal(t, float64(1), dataPoints[0].GetAsDouble())
+			require.Equal(t, "chaosresult_name", dataPoints[0].GetAttributes()[0].GetKey())
+			require.Equal(t, "chaosresult-1", dataPoints[0].GetAttributes()[0].GetValue().GetStringValue())
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	r := &receiver{}
+	exporter, err := NewExporter(Config{
+		Endpoint: startGRPCReceiver(t, r),
+		Protocol: ProtocolGRPC,
+		Interval: 10 * time.Millisecond,
+		Insecure: true,
+	}, newTestRegistry(t))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		exporter.Run(ctx)
+		close(done)
+	}()
+	require.Eventually(t, func() bool {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return len(r.requests) >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+}
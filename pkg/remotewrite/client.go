@@ -0,0 +1,301 @@
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/log"
+)
+
+// metricPrefix is the prefix of the chaos metrics, which are pushed
+const metricPrefix = "litmuschaos_"
+
+// Config contains the configuration of the remote-write client
+type Config struct {
+	URL             string
+	Interval        time.Duration
+	Timeout         time.Duration
+	Username        string
+	Password        string
+	BearerToken     string
+	BearerTokenFile string
+	ExternalLabels  string
+	QueueCapacity   int
+}
+
+// RegisterFlags registers the remote-write flags, the ENVs are used as the default values
+func (config *Config) RegisterFlags(fs *flag.FlagSet) {
+	interval, err := time.ParseDuration(os.Getenv("REMOTE_WRITE_INTERVAL"))
+	if err != nil || interval <= 0 {
+		interval = 30 * time.Second
+	}
+	capacity, err := strconv.Atoi(os.Getenv("REMOTE_WRITE_QUEUE_CAPACITY"))
+	if err != nil || capacity <= 0 {
+		capacity = 10
+	}
+	fs.StringVar(&config.URL, "remote-write-url", os.Getenv("REMOTE_WRITE_URL"), "url of the prometheus remote-write endpoint, the remote-write is disabled if empty")
+	fs.DurationVar(&config.Interval, "remote-write-interval", interval, "interval between the remote-write pushes")
+	fs.DurationVar(&config.Timeout, "remote-write-timeout", 10*time.Second, "timeout of a remote-write request")
+	fs.StringVar(&config.Username, "remote-write-username", os.Getenv("REMOTE_WRITE_USERNAME"), "username of the remote-write basic auth")
+	fs.StringVar(&config.BearerTokenFile, "remote-write-bearer-token-file", os.Getenv("REMOTE_WRITE_BEARER_TOKEN_FILE"), "file containing the remote-write bearer token, it is read before every request")
+	fs.StringVar(&config.ExternalLabels, "remote-write-external-labels", os.Getenv("REMOTE_WRITE_EXTERNAL_LABELS"), "comma separated name=value labels added to every pushed series")
+	fs.IntVar(&config.QueueCapacity, "remote-write-queue-capacity", capacity, "maximum number of the pending remote-write requests, the oldest request is dropped once it is full")
+	// the secrets are only read from the ENVs, so that those don't show up in the process arguments
+	config.Password = os.Getenv("REMOTE_WRITE_PASSWORD")
+	config.BearerToken = os.Getenv("REMOTE_WRITE_BEARER_TOKEN")
+}
+
+// Enabled checks whether the remote-write is enabled
+func (config Config) Enabled() bool {
+	return config.URL != ""
+}
+
+// label is the name value pair of a series
+type label struct {
+	name, value string
+}
+
+// sample is the timestamped value of a series
+type sample struct {
+	value     float64
+	timestamp int64
+}
+
+// timeSeries is the series pushed in the WriteRequest
+type timeSeries struct {
+	labels  []label
+	samples []sample
+}
+
+// recoverableError is returned for the failed requests which can be retried
+type recoverableError struct {
+	error
+}
+
+// Client periodically pushes the chaos metrics gathered from the prometheus registry to the remote-write endpoint
+type Client struct {
+	config         Config
+	gatherer       prometheus.Gatherer
+	externalLabels []label
+	client         *http.Client
+
+	mu sync.Mutex
+	// queue contains the encoded WriteRequests, which are yet to be sent, oldest first
+	queue [][]byte
+}
+
+// NewClient creates the remote-write client, which pushes the chaos metrics of the given gatherer
+func NewClient(config Config, gatherer prometheus.Gatherer) (*Client, error) {
+	if config.Username != "" && (config.BearerToken != "" || config.BearerTokenFile != "") {
+		return nil, errors.Errorf("remote-write basic auth and bearer token are mutually exclusive")
+	}
+	if config.QueueCapacity <= 0 {
+		config.QueueCapacity = 1
+	}
+	externalLabels, err := parseExternalLabels(config.ExternalLabels)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		config:         config,
+		gatherer:       gatherer,
+		externalLabels: externalLabels,
+		client:         &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+// parseExternalLabels parses the comma separated name=value labels
+func parseExternalLabels(value string) ([]label, error) {
+	var labels []label
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, errors.Errorf("invalid remote-write external label: %s, it must be in name=value format", pair)
+		}
+		labels = append(labels, label{name: strings.TrimSpace(parts[0]), value: strings.TrimSpace(parts[1])})
+	}
+	return labels, nil
+}
+
+// Run pushes the metrics every interval until the context is cancelled
+func (c *Client) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Push(ctx); err != nil {
+				log.Errorf("Unable to push the remote-write metrics, err: %v", err)
+			}
+		}
+	}
+}
+
+// Push enqueues the snapshot of the chaos metrics and sends all the pending requests
+// the requests failed with a recoverable error are kept in the queue and retried by the next push
+func (c *Client) Push(ctx context.Context) error {
+	families, err := c.gatherer.Gather()
+	if err != nil {
+		return errors.Wrapf(err, "unable to gather the metrics")
+	}
+	c.enqueue(snappy.Encode(nil, encodeWriteRequest(c.newTimeSeries(families, time.Now()))))
+	return c.flush(ctx)
+}
+
+// enqueue adds the request to the queue, it drops the oldest request if the queue is full
+func (c *Client) enqueue(request []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.queue) >= c.config.QueueCapacity {
+		log.Warnf("Remote-write queue is full, dropping the oldest request")
+		c.queue = c.queue[1:]
+	}
+	c.queue = append(c.queue, request)
+}
+
+// flush sends the queued requests in order, it stops at the first recoverable failure
+// the requests failed with the non recoverable error are dropped
+func (c *Client) flush(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var sendErr error
+	for len(c.queue) > 0 {
+		err := c.send(ctx, c.queue[0])
+		if _, ok := err.(recoverableError); ok {
+			return err
+		}
+		if err != nil && sendErr == nil {
+			sendErr = err
+		}
+		c.queue = c.queue[1:]
+	}
+	return sendErr
+}
+
+// pending returns the number of the queued requests
+func (c *Client) pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.queue)
+}
+
+// send sends the encoded WriteRequest to the remote-write endpoint
+func (c *Client) send(ctx context.Context, request []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.URL, bytes.NewReader(request))
+	if err != nil {
+		return errors.Wrapf(err, "unable to create the remote-write request")
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", "chaos-exporter")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	switch {
+	case c.config.Username != "":
+		req.SetBasicAuth(c.config.Username, c.config.Password)
+	case c.config.BearerTokenFile != "":
+		token, err := os.ReadFile(c.config.BearerTokenFile)
+		if err != nil {
+			return recoverableError{errors.Wrapf(err, "unable to read the remote-write bearer token file")}
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	case c.config.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+c.config.BearerToken)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return recoverableError{errors.Wrapf(err, "unable to send the remote-write request")}
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	err = errors.Errorf("remote-write request failed, status: %s, body: %s", resp.Status, string(body))
+	// the server errors and the throttled requests are retried, the other client errors are not
+	if resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests {
+		return recoverableError{err}
+	}
+	return err
+}
+
+// newTimeSeries converts the chaos gauges to the remote-write series
+func (c *Client) newTimeSeries(families []*dto.MetricFamily, now time.Time) []timeSeries {
+	timestamp := now.UnixNano() / int64(time.Millisecond)
+	var series []timeSeries
+	for _, family := range families {
+		if family.GetType() != dto.MetricType_GAUGE || !strings.HasPrefix(family.GetName(), metricPrefix) {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := []label{{name: "__name__", value: family.GetName()}}
+			names := map[string]bool{}
+			for _, l := range metric.GetLabel() {
+				labels = append(labels, label{name: l.GetName(), value: l.GetValue()})
+				names[l.GetName()] = true
+			}
+			// the external labels don't override the labels of the metric
+			for _, l := range c.externalLabels {
+				if !names[l.name] {
+					labels = append(labels, l)
+				}
+			}
+			sort.Slice(labels, func(i, j int) bool { return labels[i].name < labels[j].name })
+			series = append(series, timeSeries{
+				labels:  labels,
+				samples: []sample{{value: metric.GetGauge().GetValue(), timestamp: timestamp}},
+			})
+		}
+	}
+	return series
+}
+
+// encodeWriteRequest encodes the series as the prometheus.WriteRequest protobuf message
+func encodeWriteRequest(series []timeSeries) []byte {
+	var request []byte
+	for _, ts := range series {
+		var encoded []byte
+		for _, l := range ts.labels {
+			var encodedLabel []byte
+			encodedLabel = protowire.AppendTag(encodedLabel, 1, protowire.BytesType)
+			encodedLabel = protowire.AppendString(encodedLabel, l.name)
+			encodedLabel = protowire.AppendTag(encodedLabel, 2, protowire.BytesType)
+			encodedLabel = protowire.AppendString(encodedLabel, l.value)
+			encoded = protowire.AppendTag(encoded, 1, protowire.BytesType)
+			encoded = protowire.AppendBytes(encoded, encodedLabel)
+		}
+		for _, s := range ts.samples {
+			var encodedSample []byte
+			encodedSample = protowire.AppendTag(encodedSample, 1, protowire.Fixed64Type)
+			encodedSample = protowire.AppendFixed64(encodedSample, math.Float64bits(s.value))
+			encodedSample = protowire.AppendTag(encodedSample, 2, protowire.VarintType)
+			encodedSample = protowire.AppendVarint(encodedSample, uint64(s.timestamp))
+			encoded = protowire.AppendTag(encoded, 2, protowire.BytesType)
+			encoded = protowire.AppendBytes(encoded, encodedSample)
+		}
+		request = protowire.AppendTag(request, 1, protowire.BytesType)
+		request = protowire.AppendBytes(request, encoded)
+	}
+	return request
+}
@@ -0,0 +1,257 @@
+package remotewrite
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// receiver is a remote-write endpoint, which decodes and records the received series
+type receiver struct {
+	mu sync.Mutex
+	// statuses are the status codes returned by the upcoming requests, 204 once those are exhausted
+	statuses []int
+	// authorization contains the Authorization header of every request
+	authorization []string
+	series        [][]map[string]string
+	values        []float64
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.authorization = append(r.authorization, req.Header.Get("Authorization"))
+	if len(r.statuses) > 0 {
+		status := r.statuses[0]
+		r.statuses = r.statuses[1:]
+		w.WriteHeader(status)
+		return
+	}
+	if req.Header.Get("Content-Encoding") != "snappy" || req.Header.Get("X-Prometheus-Remote-Write-Version") != "0.1.0" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	body, _ := io.ReadAll(req.Body)
+	data, err := snappy.Decode(nil, body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var series []map[string]string
+	forEachField(data, func(_ protowire.Number, timeSeries []byte) {
+		labels := map[string]string{}
+		forEachField(timeSeries, func(num protowire.Number, value []byte) {
+			switch num {
+			case 1:
+				var name string
+				forEachField(value, func(num protowire.Number, v []byte) {
+					if num == 1 {
+						name = string(v)
+					} else {
+						labels[name] = string(v)
+					}
+				})
+			case 2:
+				// the value is the first field of the sample, encoded after its one byte tag
+				bits, _ := protowire.ConsumeFixed64(value[1:])
+				r.values = append(r.values, math.Float64frombits(bits))
+			}
+		})
+		series = append(series, labels)
+	})
+	r.series = append(r.series, series)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// forEachField calls the given function with every length delimited field of the message
+func forEachField(data []byte, fn func(num protowire.Number, value []byte)) {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		switch typ {
+		case protowire.BytesType:
+			value, m := protowire.ConsumeBytes(data[n:])
+			fn(num, value)
+			data = data[n+m:]
+		default:
+			m := protowire.ConsumeFieldValue(num, typ, data[n:])
+			data = data[n+m:]
+		}
+	}
+}
+
+// newTestRegistry creates the registry containing a chaos gauge and a non chaos metric
+func newTestRegistry(t *testing.T) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	verdict := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "litmuschaos",
+		Name:      "experiment_verdict",
+		Help:      "Verdict of the experiment",
+	}, []string{"chaosresult_name", "cluster"})
+	verdict.WithLabelValues("chaosresult-1", "metric-cluster").Set(1)
+	other := prometheus.NewCounter(prometheus.CounterOpts{Name: "other_total", Help: "other"})
+	require.NoError(t, registry.Register(verdict))
+	require.NoError(t, registry.Register(other))
+	return registry
+}
+
+func TestPush(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("file-token\n"), 0600))
+
+	tests := []struct {
+		name                  string
+		config                Config
+		statuses              []int
+		pushes                int
+		expectedAuthorization string
+		expectedRequests      int
+		expectedPending       int
+		isErr                 bool
+	}{
+		{
+			name: "success: basic auth",
+			config: Config{
+				Username:       "user",
+				Password:       "pass",
+				ExternalLabels: "cluster=edge-1, region=eu",
+				QueueCapacity:  10,
+			},
+			pushes:                1,
+			expectedAuthorization: "Basic dXNlcjpwYXNz",
+			expectedRequests:      1,
+		},
+		{
+			name: "success: bearer token file",
+			config: Config{
+				BearerTokenFile: tokenFile,
+				ExternalLabels:  "cluster=edge-1,region=eu",
+				QueueCapacity:   10,
+			},
+			pushes:                1,
+			expectedAuthorization: "Bearer file-token",
+			expectedRequests:      1,
+		},
+		{
+			name: "success: failed requests are retried by the next push",
+			config: Config{
+				BearerToken:    "token",
+				ExternalLabels: "cluster=edge-1,region=eu",
+				QueueCapacity:  10,
+			},
+			statuses:              []int{http.StatusServiceUnavailable, http.StatusTooManyRequests},
+			pushes:                3,
+			expectedAuthorization: "Bearer token",
+			expectedRequests:      3,
+		},
+		{
+			name: "success: oldest requests are dropped once the queue is full",
+			config: Config{
+				BearerToken:    "token",
+				ExternalLabels: "cluster=edge-1,region=eu",
+				QueueCapacity:  2,
+			},
+			statuses:              []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			pushes:                4,
+			expectedAuthorization: "Bearer token",
+			expectedRequests:      2,
+		},
+		{
+			name: "failure: request is dropped on the client error",
+			config: Config{
+				BearerToken:   "token",
+				QueueCapacity: 10,
+			},
+			statuses: []int{http.StatusBadRequest},
+			pushes:   1,
+			isErr:    true,
+		},
+		{
+			name: "failure: request is kept on the server error",
+			config: Config{
+				BearerToken:   "token",
+				QueueCapacity: 10,
+			},
+			statuses:        []int{http.StatusInternalServerError},
+			pushes:          1,
+			expectedPending: 1,
+			isErr:           true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &receiver{statuses: tt.statuses}
+			server := httptest.NewServer(r)
+			defer server.Close()
+			tt.config.URL = server.URL
+
+			client, err := NewClient(tt.config, newTestRegistry(t))
+			require.NoError(t, err)
+			for i := 0; i < tt.pushes; i++ {
+				err = client.Push(context.Background())
+			}
+			require.Equal(t, tt.expectedPending, client.pending())
+			if tt.isErr {
+				require.Error(t, err)
+				require.Empty(t, r.series)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedAuthorization, r.authorization[len(r.authorization)-1])
+			require.Len(t, r.series, tt.expectedRequests)
+			for _, series := range r.series {
+				// only the chaos gauges are pushed, the external labels don't override the metric labels
+				require.Equal(t, []map[string]string{{
+					"__name__":         "litmuschaos_experiment_verdict",
+					"chaosresult_name": "chaosresult-1",
+					"cluster":          "metric-cluster",
+					"region":           "eu",
+				}}, series)
+			}
+			require.Equal(t, float64(1), r.values[0])
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		isErr  bool
+	}{
+		{
+			name:   "success",
+			config: Config{URL: "http://localhost", ExternalLabels: "cluster=edge-1"},
+		},
+		{
+			name:   "failure: invalid external labels",
+			config: Config{URL: "http://localhost", ExternalLabels: "cluster"},
+			isErr:  true,
+		},
+		{
+			name:   "failure: basic auth and bearer token",
+			config: Config{URL: "http://localhost", Username: "user", BearerToken: "token"},
+			isErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewClient(tt.config, prometheus.NewRegistry())
+			if tt.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/log"
+)
+
+// certReloader serves the tls certificate and reloads it whenever
+// the certificate or key file is rotated on the disk
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu          sync.RWMutex
+	cert        *tls.Certificate
+	certModTime time.Time
+	keyModTime  time.Time
+}
+
+// newCertReloader creates the certReloader and loads the initial certificate
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	reloader := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	if err := reloader.reload(); err != nil {
+		return nil, err
+	}
+	return reloader, nil
+}
+
+// GetCertificate returns the latest certificate, it is used as tls.Config.GetCertificate
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	// keep serving the older certificate if the rotated one can't be loaded
+	if err := r.reload(); err != nil {
+		log.Errorf("unable to reload the tls certificate, err: %v", err)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// reload loads the certificate if the certificate or key file is modified since the last load
+func (r *certReloader) reload() error {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return errors.Wrapf(err, "unable to stat the tls certificate")
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return errors.Wrapf(err, "unable to stat the tls key")
+	}
+
+	r.mu.RLock()
+	modified := r.cert == nil || !certInfo.ModTime().Equal(r.certModTime) || !keyInfo.ModTime().Equal(r.keyModTime)
+	r.mu.RUnlock()
+	if !modified {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return errors.Wrapf(err, "unable to load the tls key pair")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.certModTime = certInfo.ModTime()
+	r.keyModTime = keyInfo.ModTime()
+	log.Infof("Loaded the tls certificate from %s", r.certFile)
+	return nil
+}
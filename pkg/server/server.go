@@ -0,0 +1,137 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"net/http"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/env"
+)
+
+// Config contains the configuration of the exporter http server
+type Config struct {
+	ListenAddress   string
+	MetricsPath     string
+	TLSCertFile     string
+	TLSKeyFile      string
+	TLSClientCAFile string
+}
+
+// RegisterFlags registers the http server flags, the ENVs are used as the default values
+func (config *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&config.ListenAddress, "listen-address", env.Get("LISTEN_ADDRESS", ":8080"), "address on which the metrics are exposed")
+	fs.StringVar(&config.MetricsPath, "metrics-path", env.Get("METRICS_PATH", "/metrics"), "path under which the metrics are exposed")
+	fs.StringVar(&config.TLSCertFile, "tls-cert-file", os.Getenv("TLS_CERT_FILE"), "path to the tls certificate, it is reloaded whenever the file changes")
+	fs.StringVar(&config.TLSKeyFile, "tls-key-file", os.Getenv("TLS_KEY_FILE"), "path to the tls private key, it is reloaded whenever the file changes")
+	fs.StringVar(&config.TLSClientCAFile, "tls-client-ca-file", os.Getenv("TLS_CLIENT_CA_FILE"), "path to the ca bundle used to verify the client certificates")
+}
+
+// TLSEnabled returns true if the tls certificate and key are provided
+func (config Config) TLSEnabled() bool {
+	return config.TLSCertFile != "" && config.TLSKeyFile != ""
+}
+
+// Validate validates the http server configuration
+func (config Config) Validate() error {
+	if (config.TLSCertFile == "") != (config.TLSKeyFile == "") {
+		return errors.Errorf("both tls-cert-file and tls-key-file must be provided to enable tls")
+	}
+	if config.TLSClientCAFile != "" && !config.TLSEnabled() {
+		return errors.Errorf("tls-client-ca-file requires tls-cert-file and tls-key-file to be provided")
+	}
+	return nil
+}
+
+// Server serves the exporter endpoints
+type Server struct {
+	config Config
+	mux    *http.ServeMux
+}
+
+// New creates the http server for the given configuration
+func New(config Config) *Server {
+	return &Server{
+		config: config,
+		mux:    http.NewServeMux(),
+	}
+}
+
+// Handle registers the handler for the given pattern
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// HandleMetrics registers the metrics handler under the metrics path, the verified client
+// certificate is required to access the metrics if the client ca is provided
+func (s *Server) HandleMetrics(handler http.Handler) {
+	if s.config.TLSClientCAFile != "" {
+		handler = requireClientCert(handler)
+	}
+	s.mux.Handle(s.config.MetricsPath, handler)
+}
+
+// requireClientCert rejects the requests without the verified client certificate
+func requireClientCert(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			http.Error(w, "client certificate is required", http.StatusForbidden)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// ListenAndServe starts the http server, it serves over tls if the tls certificate is provided
+func (s *Server) ListenAndServe() error {
+	if err := s.config.Validate(); err != nil {
+		return err
+	}
+	httpServer := &http.Server{
+		Addr:    s.config.ListenAddress,
+		Handler: s.mux,
+	}
+	if !s.config.TLSEnabled() {
+		return httpServer.ListenAndServe()
+	}
+
+	tlsConfig, err := s.tlsConfig()
+	if err != nil {
+		return err
+	}
+	httpServer.TLSConfig = tlsConfig
+	// certificates are served by the reloader, hence the file arguments are empty
+	return httpServer.ListenAndServeTLS("", "")
+}
+
+// tlsConfig builds the tls configuration of the http server
+func (s *Server) tlsConfig() (*tls.Config, error) {
+	reloader, err := newCertReloader(s.config.TLSCertFile, s.config.TLSKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	// verify the client certificates, if the client ca is provided
+	if s.config.TLSClientCAFile != "" {
+		caBundle, err := os.ReadFile(s.config.TLSClientCAFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read the client ca file")
+		}
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(caBundle) {
+			return nil, errors.Errorf("no valid certificate found inside %s", s.config.TLSClientCAFile)
+		}
+		tlsConfig.ClientCAs = clientCAs
+		// the client certificate is only verified during the handshake, it is required by the metrics handler
+		// so that the kubelet probes can access the health checks without the client certificate
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeKeyPair generates a self-signed key pair for the given common name and writes it to the dir
+func writeKeyPair(t *testing.T, dir, commonName string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, commonName+".crt")
+	keyFile := filepath.Join(dir, commonName+".key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return certFile, keyFile
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeKeyPair(t, dir, "exporter")
+
+	reloader, err := newCertReloader(certFile, keyFile)
+	require.NoError(t, err)
+	oldCert, err := reloader.GetCertificate(nil)
+	require.NoError(t, err)
+
+	// rotating the certificate with a newer modification time
+	rotatedCert, rotatedKey := writeKeyPair(t, dir, "rotated")
+	require.NoError(t, os.Rename(rotatedCert, certFile))
+	require.NoError(t, os.Rename(rotatedKey, keyFile))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+	require.NoError(t, os.Chtimes(keyFile, future, future))
+
+	newCert, err := reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	require.NotEqual(t, oldCert.Certificate[0], newCert.Certificate[0])
+
+	// keep serving the rotated certificate if the files are removed
+	require.NoError(t, os.Remove(certFile))
+	currentCert, err := reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, newCert.Certificate[0], currentCert.Certificate[0])
+}
+
+func TestClientCertificateVerification(t *testing.T) {
+	dir := t.TempDir()
+	serverCert, serverKey := writeKeyPair(t, dir, "localhost")
+	clientCert, clientKey := writeKeyPair(t, dir, "scraper")
+
+	srv := New(Config{
+		MetricsPath:     "/metrics",
+		TLSCertFile:     serverCert,
+		TLSKeyFile:      serverKey,
+		TLSClientCAFile: clientCert,
+	})
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	srv.HandleMetrics(okHandler)
+	srv.Handle("/healthz", okHandler)
+	tlsConfig, err := srv.tlsConfig()
+	require.NoError(t, err)
+
+	ts := httptest.NewUnstartedServer(srv.mux)
+	ts.TLS = tlsConfig
+	ts.StartTLS()
+	defer ts.Close()
+
+	serverCA, err := os.ReadFile(serverCert)
+	require.NoError(t, err)
+	rootCAs := x509.NewCertPool()
+	rootCAs.AppendCertsFromPEM(serverCA)
+
+	tests := map[string]struct {
+		path           string
+		withClientCert bool
+		expectedStatus int
+	}{
+		"success: client certificate provided": {
+			path:           "/metrics",
+			withClientCert: true,
+			expectedStatus: http.StatusOK,
+		},
+		"failure: client certificate not provided": {
+			path:           "/metrics",
+			withClientCert: false,
+			expectedStatus: http.StatusForbidden,
+		},
+		"success: health checks don't require the client certificate": {
+			path:           "/healthz",
+			withClientCert: false,
+			expectedStatus: http.StatusOK,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			clientTLSConfig := &tls.Config{RootCAs: rootCAs, ServerName: "localhost"}
+			if tt.withClientCert {
+				cert, err := tls.LoadX509KeyPair(clientCert, clientKey)
+				require.NoError(t, err)
+				clientTLSConfig.Certificates = []tls.Certificate{cert}
+			}
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLSConfig}}
+			resp, err := client.Get(ts.URL + tt.path)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, tt.expectedStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := map[string]struct {
+		config Config
+		isErr  bool
+	}{
+		"success: plain http": {
+			config: Config{ListenAddress: ":8080"},
+			isErr:  false,
+		},
+		"failure: tls key missing": {
+			config: Config{TLSCertFile: "tls.crt"},
+			isErr:  true,
+		},
+		"failure: client ca without tls": {
+			config: Config{TLSClientCAFile: "ca.crt"},
+			isErr:  true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// FormatJSON posts the payload as it is
+	FormatJSON = "json"
+	// FormatSlack posts the slack incoming webhook message
+	FormatSlack = "slack"
+	// FormatTeams posts the microsoft teams connector card
+	FormatTeams = "teams"
+)
+
+// Link is the link added to the chat messages, the url is templated with the payload fields
+// e.g, https://grafana.example.com/d/chaos?var-namespace={{ .ChaosResultNamespace }}
+type Link struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// link is the link with the parsed url template
+type link struct {
+	name string
+	url  *template.Template
+}
+
+// renderedLink is the link with the rendered url
+type renderedLink struct {
+	name, url string
+}
+
+// formatter encodes the payload as the request body
+type formatter func(payload Payload, links []renderedLink) ([]byte, error)
+
+// formatters contains the supported formatters, keyed by the format name
+var formatters = map[string]formatter{
+	FormatJSON:  formatJSON,
+	FormatSlack: formatSlack,
+	FormatTeams: formatTeams,
+}
+
+// parseLinks parses the url templates of the given links
+func parseLinks(links []Link) ([]link, error) {
+	var parsed []link
+	for _, l := range links {
+		url, err := template.New(l.Name).Option("missingkey=error").Parse(l.URL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse the url template of link %s", l.Name)
+		}
+		parsed = append(parsed, link{name: l.Name, url: url})
+	}
+	return parsed, nil
+}
+
+// renderLinks renders the url templates with the given payload
+func renderLinks(links []link, payload Payload) ([]renderedLink, error) {
+	var rendered []renderedLink
+	for _, l := range links {
+		var url bytes.Buffer
+		if err := l.url.Execute(&url, payload); err != nil {
+			return nil, errors.Wrapf(err, "unable to render the url of link %s", l.name)
+		}
+		rendered = append(rendered, renderedLink{name: l.name, url: url.String()})
+	}
+	return rendered, nil
+}
+
+// formatJSON encodes the payload as it is, the links are not included
+func formatJSON(payload Payload, _ []renderedLink) ([]byte, error) {
+	return json.Marshal(payload)
+}
+
+// fact is a name value pair summarizing the chaosresult
+type fact struct {
+	name, value string
+}
+
+// summarize returns the title and the facts summarizing the chaosresult
+func summarize(payload Payload) (string, []fact) {
+	title := fmt.Sprintf("Chaos experiment %s: %s/%s", strings.ToLower(verdictTitle(payload.Verdict)), payload.ChaosResultNamespace, payload.ChaosResultName)
+	targetApp := "-"
+	if payload.AppLabel != "" || payload.AppNamespace != "" || payload.AppKind != "" {
+		targetApp = fmt.Sprintf("%s %s in %s", payload.AppKind, payload.AppLabel, payload.AppNamespace)
+	}
+	facts := []fact{
+		{"Fault", payload.FaultName},
+		{"Verdict", fmt.Sprintf("%s → %s", payload.PreviousVerdict, payload.Verdict)},
+		{"Probe Success", fmt.Sprintf("%v%%", payload.ProbeSuccessPercentage)},
+		{"ChaosEngine", payload.ChaosEngineName},
+		{"Target App", targetApp},
+		{"Duration", fmt.Sprintf("%vs", payload.TotalDuration)},
+	}
+	if payload.WorkflowName != "" {
+		facts = append(facts, fact{"Workflow", payload.WorkflowName})
+	}
+	return title, facts
+}
+
+// verdictTitle returns the human readable verdict
+func verdictTitle(verdict string) string {
+	switch verdict {
+	case "Pass":
+		return "Passed"
+	case "Fail":
+		return "Failed"
+	default:
+		return verdict
+	}
+}
+
+// verdictColor returns the color of the message for the given verdict
+func verdictColor(verdict string) string {
+	switch verdict {
+	case "Pass":
+		return "2EB67D"
+	case "Fail":
+		return "E01E5A"
+	default:
+		return "ECB22E"
+	}
+}
+
+// formatSlack encodes the payload as the slack incoming webhook message
+func formatSlack(payload Payload, links []renderedLink) ([]byte, error) {
+	title, facts := summarize(payload)
+	type field struct {
+		Title string `json:"title"`
+		Value string `json:"value"`
+		Short bool   `json:"short"`
+	}
+	var fields []field
+	for _, f := range facts {
+		fields = append(fields, field{Title: f.name, Value: f.value, Short: true})
+	}
+	var linkTexts []string
+	for _, l := range links {
+		linkTexts = append(linkTexts, fmt.Sprintf("<%s|%s>", l.url, l.name))
+	}
+	return json.Marshal(map[string]interface{}{
+		"text": title,
+		"attachments": []map[string]interface{}{
+			{
+				"color":    "#" + verdictColor(payload.Verdict),
+				"fallback": title,
+				"fields":   fields,
+				"text":     strings.Join(linkTexts, " | "),
+				"footer":   "chaos-exporter",
+			},
+		},
+	})
+}
+
+// formatTeams encodes the payload as the microsoft teams connector card
+func formatTeams(payload Payload, links []renderedLink) ([]byte, error) {
+	title, facts := summarize(payload)
+	type teamsFact struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	var teamsFacts []teamsFact
+	for _, f := range facts {
+		teamsFacts = append(teamsFacts, teamsFact{Name: f.name, Value: f.value})
+	}
+	var actions []map[string]interface{}
+	for _, l := range links {
+		actions = append(actions, map[string]interface{}{
+			"@type":   "OpenUri",
+			"name":    l.name,
+			"targets": []map[string]string{{"os": "default", "uri": l.url}},
+		})
+	}
+	card := map[string]interface{}{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"themeColor": verdictColor(payload.Verdict),
+		"summary":    title,
+		"title":      title,
+		"sections":   []map[string]interface{}{{"facts": teamsFacts}},
+	}
+	if len(actions) > 0 {
+		card["potentialAction"] = actions
+	}
+	return json.Marshal(card)
+}
@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatters(t *testing.T) {
+	payload := newPayload(newVerdictChange("litmus", "Awaited", "Fail"))
+	links, err := parseLinks([]Link{
+		{Name: "Dashboard", URL: "https://grafana/d/chaos?var-namespace={{ .ChaosResultNamespace }}&var-result={{ .ChaosResultName }}"},
+	})
+	require.NoError(t, err)
+	rendered, err := renderLinks(links, payload)
+	require.NoError(t, err)
+	require.Equal(t, []renderedLink{{name: "Dashboard", url: "https://grafana/d/chaos?var-namespace=litmus&var-result=engine-pod-delete"}}, rendered)
+
+	tests := []struct {
+		name     string
+		format   string
+		execFunc func(t *testing.T, message map[string]interface{})
+	}{
+		{
+			name:   "success: json payload",
+			format: FormatJSON,
+			execFunc: func(t *testing.T, message map[string]interface{}) {
+				require.Equal(t, "engine-pod-delete", message["chaosResultName"])
+				require.Equal(t, "Fail", message["verdict"])
+				require.NotContains(t, message, "links")
+			},
+		},
+		{
+			name:   "success: slack message",
+			format: FormatSlack,
+			execFunc: func(t *testing.T, message map[string]interface{}) {
+				require.Equal(t, "Chaos experiment failed: litmus/engine-pod-delete", message["text"])
+				attachment := message["attachments"].([]interface{})[0].(map[string]interface{})
+				require.Equal(t, "#E01E5A", attachment["color"])
+				require.Equal(t, "<https://grafana/d/chaos?var-namespace=litmus&var-result=engine-pod-delete|Dashboard>", attachment["text"])
+				fields := map[string]interface{}{}
+				for _, f := range attachment["fields"].([]interface{}) {
+					field := f.(map[string]interface{})
+					fields[field["title"].(string)] = field["value"]
+				}
+				require.Equal(t, map[string]interface{}{
+					"Fault":         "pod-delete",
+					"Verdict":       "Awaited → Fail",
+					"Probe Success": "100%",
+					"ChaosEngine":   "engine",
+					"Target App":    "deployment app=nginx in default",
+					"Duration":      "60s",
+					"Workflow":      "workflow",
+				}, fields)
+			},
+		},
+		{
+			name:   "success: teams connector card",
+			format: FormatTeams,
+			execFunc: func(t *testing.T, message map[string]interface{}) {
+				require.Equal(t, "MessageCard", message["@type"])
+				require.Equal(t, "E01E5A", message["themeColor"])
+				require.Equal(t, "Chaos experiment failed: litmus/engine-pod-delete", message["title"])
+				facts := message["sections"].([]interface{})[0].(map[string]interface{})["facts"].([]interface{})
+				require.Equal(t, map[string]interface{}{"name": "Fault", "value": "pod-delete"}, facts[0])
+				action := message["potentialAction"].([]interface{})[0].(map[string]interface{})
+				require.Equal(t, "OpenUri", action["@type"])
+				require.Equal(t, "Dashboard", action["name"])
+				require.Equal(t, []interface{}{map[string]interface{}{"os": "default", "uri": "https://grafana/d/chaos?var-namespace=litmus&var-result=engine-pod-delete"}}, action["targets"])
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := formatters[tt.format](payload, rendered)
+			require.NoError(t, err)
+			message := map[string]interface{}{}
+			require.NoError(t, json.Unmarshal(body, &message))
+			tt.execFunc(t, message)
+		})
+	}
+
+	// the unknown payload fields are rejected while rendering the links
+	links, err = parseLinks([]Link{{Name: "Dashboard", URL: "https://grafana/d/chaos?var-cluster={{ .ClusterName }}"}})
+	require.NoError(t, err)
+	_, err = renderLinks(links, payload)
+	require.Error(t, err)
+}
@@ -0,0 +1,347 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	litmuschaosv1alpha1 "github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"github.com/litmuschaos/chaos-exporter/controller"
+	"github.com/litmuschaos/chaos-exporter/pkg/env"
+	"github.com/litmuschaos/chaos-exporter/pkg/log"
+)
+
+const (
+	// SignatureHeader contains the hex encoded HMAC-SHA256 signature of the payload, prefixed with sha256=
+	SignatureHeader = "X-Chaos-Exporter-Signature"
+	// EventHeader contains the type of the notification
+	EventHeader = "X-Chaos-Exporter-Event"
+	// verdictChangeEvent is the type of the verdict change notification
+	verdictChangeEvent = "verdict-change"
+)
+
+// Config contains the configuration of the webhook notifier
+type Config struct {
+	URLs          string
+	Format        string
+	Verdicts      string
+	RoutesFile    string
+	Secret        string
+	QueueCapacity int
+	MaxRetries    int
+	RetryBackoff  time.Duration
+	Timeout       time.Duration
+}
+
+// RegisterFlags registers the webhook flags, the ENVs are used as the default values
+func (config *Config) RegisterFlags(fs *flag.FlagSet) {
+	capacity, err := strconv.Atoi(os.Getenv("WEBHOOK_QUEUE_CAPACITY"))
+	if err != nil || capacity <= 0 {
+		capacity = 100
+	}
+	fs.StringVar(&config.URLs, "webhook-urls", os.Getenv("WEBHOOK_URLS"), "comma separated urls notified about the verdict changes of the chaosresults, which don't match any route")
+	fs.StringVar(&config.Format, "webhook-format", env.Get("WEBHOOK_FORMAT", FormatJSON), "format of the notifications sent to the webhook urls, supported values: json, slack, teams")
+	fs.StringVar(&config.Verdicts, "webhook-verdicts", os.Getenv("WEBHOOK_VERDICTS"), "comma separated verdicts notified to the webhook urls, all the verdicts are notified if empty")
+	fs.StringVar(&config.RoutesFile, "webhook-routes-file", os.Getenv("WEBHOOK_ROUTES_FILE"), "file containing the per-namespace routing rules of the webhook notifications")
+	fs.IntVar(&config.QueueCapacity, "webhook-queue-capacity", capacity, "maximum number of the pending webhook notifications, the new notifications are dropped once it is full")
+	fs.IntVar(&config.MaxRetries, "webhook-max-retries", 5, "number of retries of a failed webhook notification")
+	fs.DurationVar(&config.RetryBackoff, "webhook-retry-backoff", time.Second, "initial backoff between the retries of a failed webhook notification, it is doubled after every retry")
+	fs.DurationVar(&config.Timeout, "webhook-timeout", 10*time.Second, "timeout of a webhook request")
+	// the secret is only read from the ENV, so that it doesn't show up in the process arguments
+	config.Secret = os.Getenv("WEBHOOK_SECRET")
+}
+
+// Enabled checks whether the webhook notifications are enabled
+func (config Config) Enabled() bool {
+	return config.URLs != "" || config.RoutesFile != ""
+}
+
+// Route sends the notifications of the chaosresults inside the given namespaces to the given urls
+type Route struct {
+	Namespaces []string `json:"namespaces"`
+	URLs       []string `json:"urls"`
+	// Format is the format of the notifications, json if empty
+	Format string `json:"format,omitempty"`
+	// Verdicts are the notified verdicts, all the verdicts are notified if empty
+	Verdicts []string `json:"verdicts,omitempty"`
+}
+
+// Routes contains the per-namespace routing rules
+type Routes struct {
+	Routes []Route `json:"routes"`
+	// Links are added to the slack and teams notifications
+	Links []Link `json:"links,omitempty"`
+}
+
+// Payload is the JSON body of the verdict change notification
+type Payload struct {
+	Event                  string  `json:"event"`
+	Timestamp              string  `json:"timestamp"`
+	ChaosResultName        string  `json:"chaosResultName"`
+	ChaosResultNamespace   string  `json:"chaosResultNamespace"`
+	ChaosResultUID         string  `json:"chaosResultUID"`
+	ChaosEngineName        string  `json:"chaosEngineName"`
+	ChaosEngineContext     string  `json:"chaosEngineContext"`
+	WorkflowName           string  `json:"workflowName"`
+	FaultName              string  `json:"faultName"`
+	AppLabel               string  `json:"appLabel"`
+	AppNamespace           string  `json:"appNamespace"`
+	AppKind                string  `json:"appKind"`
+	PreviousVerdict        string  `json:"previousVerdict"`
+	Verdict                string  `json:"verdict"`
+	ProbeSuccessPercentage float64 `json:"probeSuccessPercentage"`
+	StartTime              float64 `json:"startTime"`
+	EndTime                float64 `json:"endTime"`
+	TotalDuration          float64 `json:"totalDuration"`
+}
+
+// target is the url along with the format of its notifications
+type target struct {
+	url      string
+	format   string
+	verdicts []string
+}
+
+// delivery is a notification pending to be sent to an url
+type delivery struct {
+	url  string
+	body []byte
+}
+
+// Notifier posts the verdict changes of the chaosresults to the webhooks
+// The notifications are queued and sent by Run, the failed notifications are retried with the exponential backoff
+type Notifier struct {
+	config         Config
+	defaultTargets []target
+	routes         []Route
+	links          []link
+	client         *http.Client
+	queue          chan delivery
+}
+
+// NewNotifier creates the webhook notifier, it reads the routing rules from the routes file
+func NewNotifier(config Config) (*Notifier, error) {
+	notifier := &Notifier{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		queue:  make(chan delivery, config.QueueCapacity),
+	}
+	format := config.Format
+	if format == "" {
+		format = FormatJSON
+	}
+	if _, ok := formatters[format]; !ok {
+		return nil, errors.Errorf("unsupported webhook format: %s, supported values: json, slack, teams", format)
+	}
+	verdicts := splitList(config.Verdicts)
+	for _, url := range splitList(config.URLs) {
+		notifier.defaultTargets = append(notifier.defaultTargets, target{url: url, format: format, verdicts: verdicts})
+	}
+	if config.RoutesFile != "" {
+		data, err := os.ReadFile(config.RoutesFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read the webhook routes file")
+		}
+		routes := Routes{}
+		if err := yaml.UnmarshalStrict(data, &routes); err != nil {
+			return nil, errors.Wrapf(err, "unable to parse the webhook routes file")
+		}
+		for i, route := range routes.Routes {
+			if route.Format == "" {
+				routes.Routes[i].Format = FormatJSON
+			}
+			if _, ok := formatters[routes.Routes[i].Format]; !ok {
+				return nil, errors.Errorf("unsupported webhook format: %s of route %d, supported values: json, slack, teams", route.Format, i)
+			}
+		}
+		notifier.routes = routes.Routes
+		if notifier.links, err = parseLinks(routes.Links); err != nil {
+			return nil, err
+		}
+	}
+	return notifier, nil
+}
+
+// splitList splits the comma separated list, the empty items are skipped
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// NotifyVerdictChange queues the notification if the chaosresult moved from Awaited to Pass, Fail or Stopped
+func (notifier *Notifier) NotifyVerdictChange(change controller.VerdictChange) error {
+	if !isNotifiedTransition(change.OldVerdict, change.ResultDetails.Verdict) {
+		return nil
+	}
+	payload := newPayload(change)
+	links, err := renderLinks(notifier.links, payload)
+	if err != nil {
+		return err
+	}
+	for _, t := range notifier.getTargets(change.ResultDetails.Namespace) {
+		if len(t.verdicts) != 0 && !contains(t.verdicts, payload.Verdict) {
+			continue
+		}
+		body, err := formatters[t.format](payload, links)
+		if err != nil {
+			return errors.Wrapf(err, "unable to encode the webhook payload")
+		}
+		select {
+		case notifier.queue <- delivery{url: t.url, body: body}:
+		default:
+			return errors.Errorf("webhook queue is full, dropping the notification to %s", t.url)
+		}
+	}
+	return nil
+}
+
+// contains checks whether the list contains the given item
+func contains(list []string, item string) bool {
+	for _, value := range list {
+		if value == item {
+			return true
+		}
+	}
+	return false
+}
+
+// isNotifiedTransition checks whether the verdict moved from Awaited to Pass, Fail or Stopped
+func isNotifiedTransition(oldVerdict, newVerdict string) bool {
+	if litmuschaosv1alpha1.ResultVerdict(oldVerdict) != litmuschaosv1alpha1.ResultVerdictAwaited {
+		return false
+	}
+	switch litmuschaosv1alpha1.ResultVerdict(newVerdict) {
+	case litmuschaosv1alpha1.ResultVerdictPassed, litmuschaosv1alpha1.ResultVerdictFailed, litmuschaosv1alpha1.ResultVerdictStopped:
+		return true
+	}
+	return false
+}
+
+// getTargets returns the targets of the routes matching the given namespace
+// the default targets are returned if no route matches
+func (notifier *Notifier) getTargets(namespace string) []target {
+	var targets []target
+	for _, route := range notifier.routes {
+		if !contains(route.Namespaces, namespace) && !contains(route.Namespaces, "*") {
+			continue
+		}
+		for _, url := range route.URLs {
+			targets = append(targets, target{url: url, format: route.Format, verdicts: route.Verdicts})
+		}
+	}
+	if len(targets) == 0 {
+		return notifier.defaultTargets
+	}
+	return targets
+}
+
+// newPayload derives the payload from the verdict change
+func newPayload(change controller.VerdictChange) Payload {
+	resultDetails := change.ResultDetails
+	return Payload{
+		Event:                  verdictChangeEvent,
+		Timestamp:              change.Time.UTC().Format(time.RFC3339),
+		ChaosResultName:        resultDetails.Name,
+		ChaosResultNamespace:   resultDetails.Namespace,
+		ChaosResultUID:         string(resultDetails.UID),
+		ChaosEngineName:        resultDetails.ChaosEngineName,
+		ChaosEngineContext:     resultDetails.ChaosEngineContext,
+		WorkflowName:           resultDetails.WorkflowName,
+		FaultName:              resultDetails.FaultName,
+		AppLabel:               resultDetails.AppLabel,
+		AppNamespace:           resultDetails.AppNs,
+		AppKind:                resultDetails.AppKind,
+		PreviousVerdict:        change.OldVerdict,
+		Verdict:                resultDetails.Verdict,
+		ProbeSuccessPercentage: resultDetails.ProbeSuccessPercentage,
+		StartTime:              resultDetails.StartTime,
+		EndTime:                resultDetails.EndTime,
+		TotalDuration:          resultDetails.TotalDuration,
+	}
+}
+
+// Run sends the queued notifications until the context is cancelled
+func (notifier *Notifier) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d := <-notifier.queue:
+			if err := notifier.deliver(ctx, d); err != nil {
+				log.Errorf("Unable to send the webhook notification, err: %v", err)
+			}
+		}
+	}
+}
+
+// deliver sends the notification, it retries the failed notification with the exponential backoff
+func (notifier *Notifier) deliver(ctx context.Context, d delivery) error {
+	backoff := notifier.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := notifier.send(ctx, d)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= notifier.config.MaxRetries {
+			return err
+		}
+		log.Warnf("Unable to send the webhook notification, retrying in %v, err: %v", backoff, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// send posts the notification, it returns whether the failed notification can be retried
+func (notifier *Notifier) send(ctx context.Context, d delivery) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(d.body))
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to create the webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "chaos-exporter")
+	req.Header.Set(EventHeader, verdictChangeEvent)
+	if notifier.config.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(notifier.config.Secret, d.body))
+	}
+
+	resp, err := notifier.client.Do(req)
+	if err != nil {
+		return true, errors.Wrapf(err, "unable to send the webhook request to %s", d.url)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 512))
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	// the server errors and the throttled requests are retried, the other client errors are not
+	retry := resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, errors.Errorf("webhook request to %s failed, status: %s", d.url, resp.Status)
+}
+
+// Sign returns the signature of the payload, which is sent inside the signature header
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
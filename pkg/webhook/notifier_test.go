@@ -0,0 +1,258 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/litmuschaos/chaos-exporter/controller"
+)
+
+// receiver is a webhook endpoint, which records the received notifications
+type receiver struct {
+	mu sync.Mutex
+	// statuses are the status codes returned by the upcoming requests, 200 once those are exhausted
+	statuses   []int
+	requests   int
+	payloads   []Payload
+	signatures []string
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests++
+	if len(r.statuses) > 0 {
+		status := r.statuses[0]
+		r.statuses = r.statuses[1:]
+		w.WriteHeader(status)
+		return
+	}
+	body, _ := io.ReadAll(req.Body)
+	payload := Payload{}
+	if err := json.Unmarshal(body, &payload); err != nil || req.Header.Get(EventHeader) != verdictChangeEvent {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	// the signature is verified by the receiver
+	if signature := req.Header.Get(SignatureHeader); signature != "" && signature != Sign("secret", body) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	r.payloads = append(r.payloads, payload)
+	r.signatures = append(r.signatures, req.Header.Get(SignatureHeader))
+}
+
+// newVerdictChange creates the verdict change of the chaosresult inside the given namespace
+func newVerdictChange(namespace, oldVerdict, newVerdict string) controller.VerdictChange {
+	return controller.VerdictChange{
+		ResultDetails: controller.ChaosResultDetails{
+			Name:                   "engine-pod-delete",
+			Namespace:              namespace,
+			UID:                    "UID",
+			ChaosEngineName:        "engine",
+			ChaosEngineContext:     "context",
+			WorkflowName:           "workflow",
+			FaultName:              "pod-delete",
+			AppLabel:               "app=nginx",
+			AppNs:                  "default",
+			AppKind:                "deployment",
+			Verdict:                newVerdict,
+			ProbeSuccessPercentage: 100,
+			StartTime:              10,
+			EndTime:                70,
+			TotalDuration:          60,
+		},
+		OldVerdict: oldVerdict,
+		Time:       time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestNotifyVerdictChange(t *testing.T) {
+	tests := []struct {
+		name             string
+		change           controller.VerdictChange
+		statuses         []int
+		secret           string
+		expectedRequests int
+		expectedPayloads int
+	}{
+		{
+			name:             "success: awaited to pass is notified",
+			change:           newVerdictChange("litmus", "Awaited", "Pass"),
+			expectedRequests: 1,
+			expectedPayloads: 1,
+		},
+		{
+			name:             "success: payload is signed",
+			change:           newVerdictChange("litmus", "Awaited", "Fail"),
+			secret:           "secret",
+			expectedRequests: 1,
+			expectedPayloads: 1,
+		},
+		{
+			name:             "success: failed notification is retried",
+			change:           newVerdictChange("litmus", "Awaited", "Stopped"),
+			statuses:         []int{http.StatusBadGateway, http.StatusTooManyRequests},
+			expectedRequests: 3,
+			expectedPayloads: 1,
+		},
+		{
+			name:             "success: client error is not retried",
+			change:           newVerdictChange("litmus", "Awaited", "Pass"),
+			statuses:         []int{http.StatusBadRequest},
+			expectedRequests: 1,
+		},
+		{
+			name:             "success: retries are exhausted",
+			change:           newVerdictChange("litmus", "Awaited", "Pass"),
+			statuses:         []int{500, 500, 500, 500},
+			expectedRequests: 3,
+		},
+		{
+			name:   "success: pass to awaited is not notified",
+			change: newVerdictChange("litmus", "Pass", "Awaited"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &receiver{statuses: tt.statuses}
+			server := httptest.NewServer(r)
+			defer server.Close()
+
+			notifier, err := NewNotifier(Config{
+				URLs:          server.URL,
+				Secret:        tt.secret,
+				QueueCapacity: 10,
+				MaxRetries:    2,
+				RetryBackoff:  time.Millisecond,
+				Timeout:       time.Second,
+			})
+			require.NoError(t, err)
+			require.NoError(t, notifier.NotifyVerdictChange(tt.change))
+			// only the notified transitions are queued
+			require.Equal(t, tt.expectedRequests > 0, len(notifier.queue) == 1)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go notifier.Run(ctx)
+			require.Eventually(t, func() bool {
+				r.mu.Lock()
+				defer r.mu.Unlock()
+				return r.requests == tt.expectedRequests && len(notifier.queue) == 0
+			}, 5*time.Second, time.Millisecond)
+
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			require.Len(t, r.payloads, tt.expectedPayloads)
+			if tt.expectedPayloads == 0 {
+				return
+			}
+			require.Equal(t, Payload{
+				Event:                  verdictChangeEvent,
+				Timestamp:              "2024-01-02T03:04:05Z",
+				ChaosResultName:        "engine-pod-delete",
+				ChaosResultNamespace:   "litmus",
+				ChaosResultUID:         "UID",
+				ChaosEngineName:        "engine",
+				ChaosEngineContext:     "context",
+				WorkflowName:           "workflow",
+				FaultName:              "pod-delete",
+				AppLabel:               "app=nginx",
+				AppNamespace:           "default",
+				AppKind:                "deployment",
+				PreviousVerdict:        "Awaited",
+				Verdict:                tt.change.ResultDetails.Verdict,
+				ProbeSuccessPercentage: 100,
+				StartTime:              10,
+				EndTime:                70,
+				TotalDuration:          60,
+			}, r.payloads[0])
+			if tt.secret != "" {
+				require.NotEmpty(t, r.signatures[0])
+			}
+		})
+	}
+}
+
+func TestRouting(t *testing.T) {
+	routesFile := filepath.Join(t.TempDir(), "routes.yaml")
+	require.NoError(t, os.WriteFile(routesFile, []byte(`routes:
+- namespaces: ["team-a"]
+  urls: ["http://team-a/hook"]
+- namespaces: ["team-a", "team-b"]
+  urls: ["http://teams/hook"]
+  format: teams
+  verdicts: ["Fail"]
+`), 0600))
+
+	notifier, err := NewNotifier(Config{URLs: "http://default/hook, http://audit/hook", RoutesFile: routesFile, QueueCapacity: 1})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		namespace       string
+		expectedTargets []target
+	}{
+		{
+			name:      "success: multiple routes matched",
+			namespace: "team-a",
+			expectedTargets: []target{
+				{url: "http://team-a/hook", format: FormatJSON},
+				{url: "http://teams/hook", format: FormatTeams, verdicts: []string{"Fail"}},
+			},
+		},
+		{
+			name:      "success: single route matched",
+			namespace: "team-b",
+			expectedTargets: []target{
+				{url: "http://teams/hook", format: FormatTeams, verdicts: []string{"Fail"}},
+			},
+		},
+		{
+			name:      "success: default urls used if no route matched",
+			namespace: "litmus",
+			expectedTargets: []target{
+				{url: "http://default/hook", format: FormatJSON},
+				{url: "http://audit/hook", format: FormatJSON},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expectedTargets, notifier.getTargets(tt.namespace))
+		})
+	}
+
+	// the passed verdict is not sent to the route notified only about the failures
+	require.NoError(t, notifier.NotifyVerdictChange(newVerdictChange("team-b", "Awaited", "Pass")))
+	require.Len(t, notifier.queue, 0)
+
+	// the notifications are dropped once the queue is full
+	require.Error(t, notifier.NotifyVerdictChange(newVerdictChange("team-a", "Awaited", "Fail")))
+	require.Len(t, notifier.queue, 1)
+
+	// the invalid routes files are rejected
+	for _, routes := range []string{
+		"routes:\n- namespace: team-a\n",
+		"routes:\n- namespaces: [team-a]\n  format: discord\n",
+		"links:\n- name: dashboard\n  url: http://grafana/{{ .ChaosResultName\n",
+	} {
+		require.NoError(t, os.WriteFile(routesFile, []byte(routes), 0600))
+		_, err = NewNotifier(Config{RoutesFile: routesFile})
+		require.Error(t, err)
+	}
+
+	// the unsupported default format is rejected
+	_, err = NewNotifier(Config{URLs: "http://default/hook", Format: "discord"})
+	require.Error(t, err)
+}